@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"go.uber.org/zap"
+	"log"
+	"os"
+	"poker/internal/modules/history/export"
+	"poker/internal/modules/history/repo"
+	"poker/internal/modules/history/service"
+	"poker/packages/database"
+	"time"
+)
+
+// Выгрузка истории раздач игрока тем же путём, что и GET /history/export:
+// одна раздача или все за период, текстом PokerStars или в JSON.
+//
+//	go run ./cmd/handexport -user <id> -from 2025-06-01 -to 2025-06-30 -out hands.txt
+//	go run ./cmd/handexport -user <id> -hand <room>-42 -format json
+func main() {
+	userID := flag.String("user", "", "ID игрока, для которого выгрузка (его карты открыты)")
+	hand := flag.String("hand", "", "ID раздачи")
+	from := flag.String("from", "", "первый день периода, YYYY-MM-DD (UTC)")
+	to := flag.String("to", "", "последний день периода, YYYY-MM-DD; по умолчанию сегодня")
+	formatFlag := flag.String("format", string(export.FormatPokerStars), "pokerstars или json")
+	out := flag.String("out", "-", "файл выгрузки; - — stdout")
+	flag.Parse()
+
+	if *userID == "" {
+		log.Fatalf("❌ -user is required")
+	}
+	if *hand == "" && *from == "" {
+		log.Fatalf("❌ -hand or -from is required")
+	}
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	database.DbSetup()
+	historyService := service.NewHistoryService(repo.NewHistoryRepo(database.DB), zap.NewNop())
+
+	var hands []export.Hand
+	if *hand != "" {
+		hands, err = historyService.ExportHand(*userID, *hand)
+	} else {
+		start, end, rangeErr := export.ParseRange(*from, *to, time.Now())
+		if rangeErr != nil {
+			log.Fatalf("❌ %v", rangeErr)
+		}
+		hands, err = historyService.ExportRange(*userID, start, end)
+	}
+	if err != nil {
+		log.Fatalf("❌ Failed to export hands: %v", err)
+	}
+
+	if err := writeExport(format, *userID, hands, *out); err != nil {
+		log.Fatalf("❌ Failed to write export: %v", err)
+	}
+	log.Printf("✅ Exported %d hands", len(hands))
+}
+
+// writeExport пишет выгрузку в файл out или в stdout. Файл закрывается до выхода:
+// ошибка записи на закрытии означает неполную выгрузку
+func writeExport(format export.Format, userID string, hands []export.Hand, out string) error {
+	if out == "-" {
+		return format.Write(os.Stdout, userID, hands)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := format.Write(f, userID, hands); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -5,7 +5,6 @@ import (
 	"go.uber.org/zap"
 	"poker/internal/modules/auth/dto"
 	"poker/internal/modules/auth/service"
-	"strconv"
 )
 
 type AuthHandler struct {
@@ -102,10 +101,7 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 		return c.Status(401).SendString("unauthorized")
 	}
 
-	var balance int64
-	if acc.AccountBalance.CurrentBalance != "" {
-		balance, _ = strconv.ParseInt(acc.AccountBalance.CurrentBalance, 10, 64)
-	}
+	balance := acc.AccountBalance.CurrentBalance
 
 	return c.JSON(dto.Me{
 		Id:       acc.ID,
@@ -167,10 +163,7 @@ func (h *AuthHandler) MeById(c *fiber.Ctx) error {
 		return c.Status(401).SendString("unauthorized")
 	}
 
-	var balance int64
-	if acc.AccountBalance.CurrentBalance != "" {
-		balance, _ = strconv.ParseInt(acc.AccountBalance.CurrentBalance, 10, 64)
-	}
+	balance := acc.AccountBalance.CurrentBalance
 
 	return c.JSON(dto.Me{
 		Id:       acc.ID,
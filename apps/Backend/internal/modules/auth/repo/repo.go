@@ -2,9 +2,9 @@ package repo
 
 import (
 	"errors"
-	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"poker/internal/modules/ledger"
 	"poker/packages/database"
 )
 
@@ -50,10 +50,11 @@ func (r *authRepo) MeByID(id string) (database.Account, error) {
 	return account, nil
 }
 
+// UpdateBalance выставляет баланс кошелька корректирующей проводкой в книге фишек
 func (r *authRepo) UpdateBalance(userID string, newBalance int64) error {
-	return r.db.Model(&database.AccountBalance{}).
-		Where("user_id = ?", userID).
-		Update("current_balance", fmt.Sprintf("%d", newBalance)).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return ledger.Adjust(tx, ledger.Wallet(userID), newBalance, userID)
+	})
 }
 
 func (r *authRepo) UpdateElo(userID string, deltaElo int64, won bool) error {
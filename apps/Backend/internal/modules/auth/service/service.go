@@ -50,7 +50,7 @@ func (s *authService) Register(email, password, username string) error {
 	balance := &database.AccountBalance{
 		ID:             generateUUID(),
 		UserID:         account.ID,
-		CurrentBalance: "0",
+		CurrentBalance: 0,
 	}
 
 	return s.repo.CreateBalance(balance)
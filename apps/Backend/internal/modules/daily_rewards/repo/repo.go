@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"poker/internal/modules/daily_rewards/utils"
+	"poker/internal/modules/ledger"
 	"poker/packages/database"
 	"time"
 )
@@ -161,16 +162,10 @@ func (r DailyRewardRepo) SaveRewardWithBalanceAndStatistic(reward database.Rewar
 			return err
 		}
 
-		res := tx.Exec(`
-			UPDATE account_balances
-			SET current_balance = (CAST(current_balance AS BIGINT) + ?)::TEXT
-			WHERE user_id = ?`, reward.Amount, reward.UserID)
-
-		if res.Error != nil {
-			return res.Error
-		}
-		if res.RowsAffected == 0 {
-			return fmt.Errorf("account balance not found for user %s", reward.UserID)
+		posting := ledger.Posting{Reason: ledger.ReasonDailyReward, Reference: reward.ID, Key: "daily-reward/" + reward.ID}
+		_, err := ledger.Transfer(tx, posting, ledger.Rewards, ledger.Wallet(reward.UserID), int64(reward.Amount))
+		if err != nil {
+			return err
 		}
 
 		stat := database.RewardStatistic{
@@ -0,0 +1,97 @@
+package dto
+
+import "encoding/json"
+
+// HandSummary — раздача в списке истории игрока
+type HandSummary struct {
+	ID               string `json:"id"`
+	RoomID           string `json:"room_id"`
+	Hand             int    `json:"hand"`
+	GameType         string `json:"game_type"`
+	BettingStructure string `json:"betting_structure"`
+	Blinds           string `json:"blinds"`
+	Pot              int64  `json:"pot"`
+	Rake             int64  `json:"rake"`
+	Street           string `json:"street"` // где раздача закончилась: улица или showdown
+	Board            string `json:"board"`
+	Cards            string `json:"cards"` // карты игрока
+	Won              int64  `json:"won"`
+	StartedAt        int64  `json:"started_at"`
+}
+
+type HandList struct {
+	Hands  []HandSummary `json:"hands"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// HandPlayer — участник раздачи. Чужие закрытые карты видны, только если игрок дошёл до вскрытия
+type HandPlayer struct {
+	UserID   string `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Seat     int    `json:"seat"`
+	Stack    int64  `json:"stack"` // стек в начале раздачи
+	Cards    string `json:"cards,omitempty"`
+	UpCards  string `json:"up_cards,omitempty"`
+	Folded   bool   `json:"folded"`
+	AllIn    bool   `json:"all_in"`
+	Won      int64  `json:"won"`
+	Hand     string `json:"hand,omitempty"`
+}
+
+// HandAction — действие раздачи по порядку
+type HandAction struct {
+	Seq       int    `json:"seq"`
+	Street    string `json:"street"`
+	UserID    string `json:"user_id"`
+	Action    string `json:"action"`
+	Amount    int64  `json:"amount"`
+	Total     int64  `json:"total"`
+	AllIn     bool   `json:"all_in,omitempty"`
+	Discarded int    `json:"discarded,omitempty"`
+	At        int64  `json:"at"`
+}
+
+// HandDetails — полная история раздачи
+type HandDetails struct {
+	HandSummary
+	Dealer  string          `json:"dealer"`
+	Players []HandPlayer    `json:"players"`
+	Actions []HandAction    `json:"actions"`
+	Results json.RawMessage `json:"results"` // банки и выплаты
+}
+
+// ReplayFrame — стол после очередного шага раздачи. State содержит те же ключи,
+// что и живое update-game-state, включая playerCards с картами, которые видит игрок
+type ReplayFrame struct {
+	Seq    int             `json:"seq"`
+	Street string          `json:"street"`
+	Action *HandAction     `json:"action"` // действие, после которого снят кадр; null — раздача карт
+	State  json.RawMessage `json:"state"`
+	At     int64           `json:"at"`
+}
+
+// HandReplay — раздача по кадрам для пошагового повтора
+type HandReplay struct {
+	HandSummary
+	Dealer  string        `json:"dealer"`
+	Players []HandPlayer  `json:"players"`
+	Frames  []ReplayFrame `json:"frames"`
+}
+
+// ShareRequest — что открыть в публичной ссылке на раздачу
+type ShareRequest struct {
+	ShowNames       bool `json:"show_names"`        // ники остальных игроков
+	ShowFoldedCards bool `json:"show_folded_cards"` // свои карты, даже если сброшены
+}
+
+// HandShare — публичная ссылка на раздачу. Открывается без токена по адресу Path
+type HandShare struct {
+	Token           string `json:"token"`
+	HandID          string `json:"hand_id"`
+	Path            string `json:"path"`
+	ShowNames       bool   `json:"show_names"`
+	ShowFoldedCards bool   `json:"show_folded_cards"`
+	CreatedAt       int64  `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Format — формат выгрузки
+type Format string
+
+const (
+	FormatPokerStars Format = "pokerstars"
+	FormatJSON       Format = "json"
+)
+
+const dateLayout = "2006-01-02"
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatPokerStars:
+		return FormatPokerStars, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown export format %q: want %s or %s", s, FormatPokerStars, FormatJSON)
+}
+
+// ContentType — MIME-тип файла выгрузки
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Extension — расширение файла выгрузки
+func (f Format) Extension() string {
+	if f == FormatJSON {
+		return "json"
+	}
+	return "txt"
+}
+
+// Write пишет раздачи, выгруженные для userID, в формате f
+func (f Format) Write(w io.Writer, userID string, hands []Hand) error {
+	if f == FormatJSON {
+		return WriteJSON(w, userID, hands)
+	}
+	return WritePokerStars(w, hands)
+}
+
+// ParseRange разбирает даты "YYYY-MM-DD" (UTC) в полуинтервал [from, to):
+// to включается целиком, без to — по сегодняшний день
+func ParseRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from date %q: want YYYY-MM-DD", from)
+	}
+	end := now.UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		if end, err = time.Parse(dateLayout, to); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date %q: want YYYY-MM-DD", to)
+		}
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,214 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"poker/packages/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion — версия JSON-формата выгрузки, описан в hand.schema.json
+const SchemaVersion = "poker-hand-history/v1"
+
+// Hand — раздача в том виде, в каком её видит один игрок: его карты открыты,
+// чужие — только если их показали на вскрытии. Из неё строятся оба формата выгрузки
+type Hand struct {
+	Schema           string    `json:"schema"`
+	ID               string    `json:"id"`
+	Number           int       `json:"hand_number"`
+	RoomID           string    `json:"room_id"`
+	GameType         string    `json:"game_type"`
+	BettingStructure string    `json:"betting_structure"`
+	Blinds           Blinds    `json:"blinds"`
+	StartedAt        time.Time `json:"started_at"`
+	ButtonSeat       int       `json:"button_seat"`
+	EndedOn          string    `json:"ended_on"` // улица, на которой раздача закончилась, или showdown
+	Board            []string  `json:"board"`
+	Pot              int64     `json:"pot"`
+	Rake             int64     `json:"rake"`
+	Players          []Player  `json:"players"`
+	Actions          []Action  `json:"actions"`
+	Pots             []Pot     `json:"pots"`
+}
+
+type Blinds struct {
+	Small int64 `json:"small"`
+	Big   int64 `json:"big"`
+	Ante  int64 `json:"ante"`
+}
+
+type Player struct {
+	Seat    int      `json:"seat"`
+	UserID  string   `json:"user_id"`
+	Name    string   `json:"name"`
+	Hero    bool     `json:"hero"` // тот, для кого выгрузка
+	Stack   int64    `json:"stack"`
+	Cards   []string `json:"cards"` // null — карты скрыты
+	UpCards []string `json:"up_cards"`
+	Folded  bool     `json:"folded"`
+	AllIn   bool     `json:"all_in"`
+	Won     int64    `json:"won"`
+	Hand    string   `json:"hand,omitempty"`
+}
+
+type Action struct {
+	Seq       int    `json:"seq"`
+	Street    string `json:"street"`
+	UserID    string `json:"user_id"`
+	Action    string `json:"action"`
+	Amount    int64  `json:"amount"` // фишек добавлено в банк
+	Total     int64  `json:"total"`  // ставка игрока на улице после действия
+	AllIn     bool   `json:"all_in"`
+	Discarded int    `json:"discarded"`
+}
+
+type Pot struct {
+	Amount  int64    `json:"amount"`
+	Rake    int64    `json:"rake"`
+	Winners []Winner `json:"winners"`
+}
+
+type Winner struct {
+	UserID string `json:"user_id"`
+	Amount int64  `json:"amount"`
+	Hand   string `json:"hand,omitempty"`
+	Side   string `json:"side,omitempty"` // хай-лоу: "hi" или "lo"
+}
+
+// storedPot — банк в том виде, в каком его сохраняет стол (GameSession.Results)
+type storedPot struct {
+	Amount  int64 `json:"amount"`
+	Rake    int64 `json:"rake"`
+	Winners []struct {
+		UserID string `json:"userId"`
+		Amount int64  `json:"amount"`
+		Hand   string `json:"hand"`
+		Side   string `json:"side"`
+	} `json:"winners"`
+}
+
+// CardsVisible — видит ли viewer закрытые карты игрока p
+func CardsVisible(session database.GameSession, p database.GamePlayer, viewer string) bool {
+	return p.UserID == viewer || (session.Status == "showdown" && !p.IsFolded)
+}
+
+// FromSession строит раздачу для viewer из сохранённой истории
+func FromSession(session database.GameSession, viewer string) (Hand, error) {
+	h := Hand{
+		Schema:           SchemaVersion,
+		ID:               session.ID,
+		Number:           session.Round,
+		RoomID:           session.RoomID,
+		GameType:         session.GameType,
+		BettingStructure: session.BettingStructure,
+		Blinds:           parseBlinds(session.Blinds),
+		StartedAt:        time.Unix(session.StartedAt, 0).UTC(),
+		EndedOn:          session.Status,
+		Board:            splitCards(session.Board),
+		Pot:              session.Pot,
+		Rake:             session.Rake,
+		Players:          make([]Player, 0, len(session.Players)),
+		Actions:          make([]Action, 0, len(session.Moves)),
+	}
+
+	for _, p := range session.Players {
+		name := p.User.Username
+		if name == "" {
+			name = p.UserID
+		}
+		player := Player{
+			Seat:    p.SeatNumber,
+			UserID:  p.UserID,
+			Name:    name,
+			Hero:    p.UserID == viewer,
+			Stack:   p.Chips,
+			UpCards: splitCards(p.UpCards),
+			Folded:  p.IsFolded,
+			AllIn:   p.IsAllIn,
+			Won:     p.Won,
+			Hand:    p.Hand,
+		}
+		if CardsVisible(session, p, viewer) {
+			player.Cards = splitCards(p.HoleCards)
+		}
+		if p.UserID == session.Dealer {
+			h.ButtonSeat = p.SeatNumber
+		}
+		h.Players = append(h.Players, player)
+	}
+
+	for _, m := range session.Moves {
+		h.Actions = append(h.Actions, Action{
+			Seq:       m.Seq,
+			Street:    m.Street,
+			UserID:    m.PlayerID,
+			Action:    m.Action,
+			Amount:    m.Amount,
+			Total:     m.Total,
+			AllIn:     m.IsAllIn,
+			Discarded: m.Discarded,
+		})
+	}
+
+	var stored []storedPot
+	if session.Results != "" {
+		if err := json.Unmarshal([]byte(session.Results), &stored); err != nil {
+			return Hand{}, fmt.Errorf("hand %s: invalid results: %w", session.ID, err)
+		}
+	}
+	h.Pots = make([]Pot, 0, len(stored))
+	for _, sp := range stored {
+		pot := Pot{Amount: sp.Amount, Rake: sp.Rake, Winners: make([]Winner, 0, len(sp.Winners))}
+		for _, w := range sp.Winners {
+			pot.Winners = append(pot.Winners, Winner{UserID: w.UserID, Amount: w.Amount, Hand: w.Hand, Side: w.Side})
+		}
+		h.Pots = append(h.Pots, pot)
+	}
+	return h, nil
+}
+
+// player — участник по ID
+func (h Hand) player(userID string) (Player, bool) {
+	for _, p := range h.Players {
+		if p.UserID == userID {
+			return p, true
+		}
+	}
+	return Player{}, false
+}
+
+// name — имя игрока в тексте истории
+func (h Hand) name(userID string) string {
+	if p, ok := h.player(userID); ok {
+		return p.Name
+	}
+	return userID
+}
+
+func (h Hand) hero() (Player, bool) {
+	for _, p := range h.Players {
+		if p.Hero {
+			return p, true
+		}
+	}
+	return Player{}, false
+}
+
+// parseBlinds разбирает "1/2" или "1/2/1" с анте
+func parseBlinds(s string) Blinds {
+	parts := strings.Split(s, "/")
+	values := make([]int64, 3)
+	for i := 0; i < len(parts) && i < len(values); i++ {
+		values[i], _ = strconv.ParseInt(strings.TrimSpace(parts[i]), 10, 64)
+	}
+	return Blinds{Small: values[0], Big: values[1], Ante: values[2]}
+}
+
+func splitCards(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Fields(s)
+}
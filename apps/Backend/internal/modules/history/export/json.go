@@ -0,0 +1,29 @@
+package export
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+)
+
+// Schema — JSON Schema формата выгрузки SchemaVersion
+//
+//go:embed hand.schema.json
+var Schema []byte
+
+// Export — выгрузка раздач в JSON
+type Export struct {
+	Schema string `json:"schema"`
+	UserID string `json:"user_id"` // для кого выгрузка: его карты открыты
+	Hands  []Hand `json:"hands"`
+}
+
+// WriteJSON пишет раздачи одним JSON-документом по схеме Schema
+func WriteJSON(w io.Writer, userID string, hands []Hand) error {
+	if hands == nil {
+		hands = []Hand{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Export{Schema: SchemaVersion, UserID: userID, Hands: hands})
+}
@@ -0,0 +1,477 @@
+package export
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"poker/internal/modules/room/variant"
+	"strings"
+)
+
+// Названия игр и лимитов так, как их пишет PokerStars — по ним трекеры узнают формат
+var (
+	starsGames = map[string]string{
+		string(variant.Holdem):     "Hold'em",
+		string(variant.Omaha):      "Omaha",
+		string(variant.HoldemHiLo): "Hold'em Hi/Lo",
+		string(variant.OmahaHiLo):  "Omaha Hi/Lo",
+		string(variant.ShortDeck):  "6+ Hold'em",
+		string(variant.Stud):       "7 Card Stud",
+		string(variant.StudHiLo):   "7 Card Stud Hi/Lo",
+		string(variant.TripleDraw): "Triple Draw 2-7 Lowball",
+	}
+	starsLimits = map[string]string{
+		string(variant.NoLimit):    "No Limit",
+		string(variant.PotLimit):   "Pot Limit",
+		string(variant.FixedLimit): "Limit",
+	}
+	starsStreets = map[string]string{
+		"flop":    "FLOP",
+		"turn":    "TURN",
+		"river":   "RIVER",
+		"third":   "3rd STREET",
+		"fourth":  "4th STREET",
+		"fifth":   "5th STREET",
+		"sixth":   "6th STREET",
+		"seventh": "RIVER",
+		"predraw": "DEALING HANDS",
+		"draw-1":  "FIRST DRAW",
+		"draw-2":  "SECOND DRAW",
+		"draw-3":  "THIRD DRAW",
+	}
+)
+
+// WritePokerStars пишет раздачи текстом в формате историй PokerStars, раздачи разделены пустыми строками
+func WritePokerStars(w io.Writer, hands []Hand) error {
+	for i, h := range hands {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, PokerStars(h)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PokerStars — одна раздача в формате истории PokerStars. Фишки игровые, поэтому без валюты
+func PokerStars(h Hand) string {
+	r := starsRenderer{hand: h, variant: variant.Get(variant.GameType(h.GameType))}
+	return r.render()
+}
+
+type starsRenderer struct {
+	hand    Hand
+	variant variant.Variant
+	b       strings.Builder
+}
+
+func (r *starsRenderer) line(format string, args ...interface{}) {
+	fmt.Fprintf(&r.b, format, args...)
+	r.b.WriteString("\n")
+}
+
+func (r *starsRenderer) render() string {
+	h := r.hand
+	r.header()
+
+	streets := r.variant.Streets
+	actions := h.Actions
+	reached := r.reachedStreets()
+	uncalled, uncalledTo := r.uncalledBet()
+
+	for i, street := range streets {
+		if i >= reached {
+			break
+		}
+		r.streetHeader(i)
+
+		// Ставка, которую надо уравнять на улице; на первой её поднимают уже блайнды
+		var toCall int64
+		for len(actions) > 0 && actions[0].Street == street.Name {
+			a := actions[0]
+			actions = actions[1:]
+			toCall = r.action(a, toCall)
+		}
+	}
+
+	if uncalled > 0 {
+		r.line("Uncalled bet (%d) returned to %s", uncalled, h.name(uncalledTo))
+	}
+	r.showdown(uncalled, uncalledTo)
+	r.summary(uncalled, uncalledTo)
+	return r.b.String()
+}
+
+func (r *starsRenderer) header() {
+	h := r.hand
+	game := starsGames[h.GameType]
+	if game == "" {
+		game = h.GameType
+	}
+	limit := starsLimits[h.BettingStructure]
+
+	stakes := fmt.Sprintf("%d/%d", h.Blinds.Small, h.Blinds.Big)
+	if r.variant.Stud {
+		// В стаде вместо блайндов — малая и большая ставка
+		stakes = fmt.Sprintf("%d/%d", h.Blinds.Big, h.Blinds.Big*2)
+	}
+	r.line("PokerStars Hand #%d: %s %s (%s) - %s UTC", handNumber(h.ID), game, limit, stakes, h.StartedAt.Format("2006/01/02 15:04:05"))
+
+	maxSeat := len(h.Players)
+	for _, p := range h.Players {
+		if p.Seat > maxSeat {
+			maxSeat = p.Seat
+		}
+	}
+	if r.variant.Stud {
+		r.line("Table '%s' %d-max", h.RoomID, maxSeat)
+	} else {
+		r.line("Table '%s' %d-max Seat #%d is the button", h.RoomID, maxSeat, h.ButtonSeat)
+	}
+	for _, p := range h.Players {
+		r.line("Seat %d: %s (%d in chips)", p.Seat, p.Name, p.Stack)
+	}
+}
+
+// reachedStreets — сколько улиц раздачи было сыграно или сдано
+func (r *starsRenderer) reachedStreets() int {
+	h := r.hand
+	streets := r.variant.Streets
+	reached := 1
+	for i, s := range streets {
+		if s.Name == h.EndedOn {
+			reached = i + 1
+		}
+		for _, a := range h.Actions {
+			if a.Street == s.Name && i+1 > reached {
+				reached = i + 1
+			}
+		}
+	}
+	if h.EndedOn == "showdown" {
+		reached = len(streets)
+	}
+	return reached
+}
+
+// streetHeader — заголовок улицы и карты, которые на ней сдали
+func (r *starsRenderer) streetHeader(i int) {
+	h := r.hand
+	street := r.variant.Streets[i]
+	hero, hasHero := h.hero()
+
+	switch {
+	case r.variant.Stud:
+		if i == 0 {
+			r.blindsAndAntes()
+		}
+		r.line("*** %s ***", starsStreets[street.Name])
+		r.studCards(i)
+
+	case i == 0:
+		// Блайнды и анте ставятся до раздачи карт
+		r.blindsAndAntes()
+		if r.isDraw() {
+			r.line("*** %s ***", starsStreets[street.Name])
+		} else {
+			r.line("*** HOLE CARDS ***")
+		}
+		// В дро сохраняется только итоговая рука — она видна на вскрытии и в итогах
+		if hasHero && hero.Cards != nil && !r.isDraw() {
+			r.line("Dealt to %s [%s]", hero.Name, strings.Join(hero.Cards, " "))
+		}
+
+	case street.Draw:
+		r.line("*** %s ***", starsStreets[street.Name])
+
+	default:
+		// Борд: уже открытые карты и новые
+		shown := 0
+		for _, s := range r.variant.Streets[:i] {
+			shown += s.Board
+		}
+		if shown+street.Board > len(h.Board) {
+			r.line("*** %s ***", starsStreets[street.Name])
+			return
+		}
+		dealt := h.Board[shown : shown+street.Board]
+		if shown == 0 {
+			r.line("*** %s *** [%s]", starsStreets[street.Name], strings.Join(dealt, " "))
+		} else {
+			r.line("*** %s *** [%s] [%s]", starsStreets[street.Name], strings.Join(h.Board[:shown], " "), strings.Join(dealt, " "))
+		}
+	}
+}
+
+// blindsAndAntes выводит обязательные ставки до первой улицы
+func (r *starsRenderer) blindsAndAntes() {
+	for _, a := range r.hand.Actions {
+		if a.Street != r.variant.Streets[0].Name {
+			continue
+		}
+		name := r.hand.name(a.UserID)
+		switch a.Action {
+		case "ante":
+			r.line("%s: posts the ante %d%s", name, a.Amount, allIn(a))
+		case "small-blind":
+			r.line("%s: posts small blind %d%s", name, a.Amount, allIn(a))
+		case "big-blind":
+			r.line("%s: posts big blind %d%s", name, a.Amount, allIn(a))
+		}
+	}
+}
+
+// studCards — карты улицы стада: герою — все его новые, остальным — открытые
+func (r *starsRenderer) studCards(i int) {
+	for _, p := range r.hand.Players {
+		if p.Hero && p.Cards != nil {
+			// Закрытые две, затем открытые по порядку, седьмая — снова закрытая
+			var prev, dealt []string
+			switch {
+			case i == 0 && len(p.Cards) >= 3:
+				dealt = p.Cards[:3]
+			case i > 0 && len(p.Cards) >= i+3:
+				prev, dealt = p.Cards[:i+2], p.Cards[i+2:i+3]
+			}
+			if len(dealt) == 0 {
+				continue
+			}
+			if len(prev) == 0 {
+				r.line("Dealt to %s [%s]", p.Name, strings.Join(dealt, " "))
+			} else {
+				r.line("Dealt to %s [%s] [%s]", p.Name, strings.Join(prev, " "), strings.Join(dealt, " "))
+			}
+			continue
+		}
+		// На третьей улице открыта одна карта, дальше по одной на каждой до шестой
+		if i < len(p.UpCards) {
+			r.line("Dealt to %s [%s]", p.Name, p.UpCards[i])
+		}
+	}
+}
+
+func (r *starsRenderer) isDraw() bool {
+	for _, s := range r.variant.Streets {
+		if s.Draw {
+			return true
+		}
+	}
+	return false
+}
+
+// action выводит действие и возвращает ставку, которую теперь надо уравнять
+func (r *starsRenderer) action(a Action, toCall int64) int64 {
+	name := r.hand.name(a.UserID)
+	switch a.Action {
+	case "ante", "small-blind", "big-blind":
+		// Уже выведены до раздачи карт
+		if a.Total > toCall {
+			return a.Total
+		}
+		return toCall
+	case "bring-in":
+		r.line("%s: brings in for %d%s", name, a.Amount, allIn(a))
+	case "fold":
+		r.line("%s: folds", name)
+	case "check":
+		r.line("%s: checks", name)
+	case "draw":
+		if a.Discarded == 0 {
+			r.line("%s: stands pat", name)
+		} else {
+			r.line("%s: discards %d card%s", name, a.Discarded, plural(a.Discarded))
+		}
+	default:
+		// call / bet / raise / allin — по тому, как изменилась ставка
+		switch {
+		case a.Total > toCall && toCall == 0:
+			r.line("%s: bets %d%s", name, a.Amount, allIn(a))
+		case a.Total > toCall:
+			r.line("%s: raises %d to %d%s", name, a.Total-toCall, a.Total, allIn(a))
+		default:
+			r.line("%s: calls %d%s", name, a.Amount, allIn(a))
+		}
+	}
+	if a.Total > toCall {
+		return a.Total
+	}
+	return toCall
+}
+
+// uncalledBet — несколлированная часть самой крупной ставки и кому она вернулась
+func (r *starsRenderer) uncalledBet() (int64, string) {
+	invested := make(map[string]int64)
+	for _, a := range r.hand.Actions {
+		invested[a.UserID] += a.Amount
+	}
+	var (
+		top           string
+		first, second int64
+	)
+	for _, p := range r.hand.Players {
+		amount := invested[p.UserID]
+		switch {
+		case amount > first:
+			top, first, second = p.UserID, amount, first
+		case amount > second:
+			second = amount
+		}
+	}
+	return first - second, top
+}
+
+// collected — сколько игрок забрал из банка i, без возвращённой несколлированной ставки
+func (r *starsRenderer) collected(i int, w Winner, uncalled int64, uncalledTo string) int64 {
+	if i == len(r.hand.Pots)-1 && w.UserID == uncalledTo {
+		return w.Amount - uncalled
+	}
+	return w.Amount
+}
+
+func (r *starsRenderer) showdown(uncalled int64, uncalledTo string) {
+	h := r.hand
+	if h.EndedOn == "showdown" {
+		r.line("*** SHOW DOWN ***")
+		for _, p := range h.Players {
+			if p.Folded || p.Cards == nil {
+				continue
+			}
+			if p.Hand != "" {
+				r.line("%s: shows [%s] (%s)", p.Name, strings.Join(p.Cards, " "), p.Hand)
+			} else {
+				r.line("%s: shows [%s]", p.Name, strings.Join(p.Cards, " "))
+			}
+		}
+	}
+
+	for i, pot := range h.Pots {
+		for _, w := range pot.Winners {
+			amount := r.collected(i, w, uncalled, uncalledTo)
+			if amount <= 0 {
+				continue
+			}
+			r.line("%s collected %d from %s", h.name(w.UserID), amount, potName(i, len(h.Pots)))
+		}
+	}
+}
+
+func (r *starsRenderer) summary(uncalled int64, uncalledTo string) {
+	h := r.hand
+	r.line("*** SUMMARY ***")
+
+	total := h.Pot - uncalled
+	if len(h.Pots) > 1 {
+		parts := make([]string, 0, len(h.Pots))
+		for i, pot := range h.Pots {
+			amount := pot.Amount
+			if i == len(h.Pots)-1 {
+				amount -= uncalled
+			}
+			if amount > 0 {
+				parts = append(parts, fmt.Sprintf("%s %d.", capitalize(potName(i, len(h.Pots))), amount))
+			}
+		}
+		r.line("Total pot %d %s | Rake %d", total, strings.Join(parts, " "), h.Rake)
+	} else {
+		r.line("Total pot %d | Rake %d", total, h.Rake)
+	}
+	if len(h.Board) > 0 {
+		r.line("Board [%s]", strings.Join(h.Board, " "))
+	}
+
+	won := make(map[string]int64)
+	for i, pot := range h.Pots {
+		for _, w := range pot.Winners {
+			won[w.UserID] += r.collected(i, w, uncalled, uncalledTo)
+		}
+	}
+	foldedOn := make(map[string]string)
+	for _, a := range h.Actions {
+		if a.Action == "fold" {
+			foldedOn[a.UserID] = a.Street
+		}
+	}
+
+	for _, p := range h.Players {
+		seat := fmt.Sprintf("Seat %d: %s", p.Seat, p.Name)
+		if p.Seat == h.ButtonSeat && !r.variant.Stud {
+			seat += " (button)"
+		}
+		switch {
+		case p.Folded:
+			r.line("%s folded %s", seat, r.foldedWhen(foldedOn[p.UserID]))
+		case h.EndedOn == "showdown" && p.Cards != nil && won[p.UserID] > 0:
+			r.line("%s showed [%s] and won (%d)%s", seat, strings.Join(p.Cards, " "), won[p.UserID], with(p.Hand))
+		case h.EndedOn == "showdown" && p.Cards != nil:
+			r.line("%s showed [%s] and lost%s", seat, strings.Join(p.Cards, " "), with(p.Hand))
+		case won[p.UserID] > 0:
+			r.line("%s collected (%d)", seat, won[p.UserID])
+		default:
+			r.line("%s mucked", seat)
+		}
+	}
+}
+
+// foldedWhen — "before Flop" / "on the Turn"; в стаде и дро — по названию улицы
+func (r *starsRenderer) foldedWhen(street string) string {
+	if street == r.variant.Streets[0].Name {
+		if r.variant.Stud || r.isDraw() {
+			return "on the " + capitalize(strings.ToLower(starsStreets[street]))
+		}
+		return "before Flop"
+	}
+	name := starsStreets[street]
+	if name == "" {
+		name = street
+	}
+	return "on the " + capitalize(strings.ToLower(name))
+}
+
+// handNumber — числовой номер раздачи для трекеров, стабильный для одного ID
+func handNumber(id string) uint64 {
+	f := fnv.New64a()
+	f.Write([]byte(id))
+	return f.Sum64() % 1_000_000_000_000
+}
+
+func potName(i, n int) string {
+	switch {
+	case n == 1:
+		return "pot"
+	case i == 0:
+		return "main pot"
+	default:
+		return fmt.Sprintf("side pot-%d", i)
+	}
+}
+
+func allIn(a Action) string {
+	if a.AllIn {
+		return " and is all-in"
+	}
+	return ""
+}
+
+func with(hand string) string {
+	if hand == "" {
+		return ""
+	}
+	return " with " + hand
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,56 @@
+package export
+
+import "fmt"
+
+// PublicOptions — что владелец разрешил показать в публичной ссылке
+type PublicOptions struct {
+	ShowNames       bool // ники остальных игроков
+	ShowFoldedCards bool // свои карты, даже если сброшены
+}
+
+// Public — раздача для всех, у кого есть ссылка. Строится из раздачи глазами владельца,
+// поэтому чужих карт в ней не больше, чем видел он сам. ID игроков заменены местами,
+// ники остальных и сброшенные карты скрыты, если владелец их не открыл
+func Public(h Hand, opts PublicOptions) Hand {
+	aliases := make(map[string]string, len(h.Players))
+	players := make([]Player, len(h.Players))
+	for i, p := range h.Players {
+		aliases[p.UserID] = fmt.Sprintf("seat-%d", p.Seat)
+		p.UserID = aliases[p.UserID]
+		if !p.Hero && !opts.ShowNames {
+			p.Name = fmt.Sprintf("Seat %d", p.Seat)
+		}
+		if p.Folded && !opts.ShowFoldedCards {
+			p.Cards = nil
+		}
+		players[i] = p
+	}
+	h.Players = players
+
+	alias := func(userID string) string {
+		if a, ok := aliases[userID]; ok {
+			return a
+		}
+		return "unknown"
+	}
+
+	actions := make([]Action, len(h.Actions))
+	for i, a := range h.Actions {
+		a.UserID = alias(a.UserID)
+		actions[i] = a
+	}
+	h.Actions = actions
+
+	pots := make([]Pot, len(h.Pots))
+	for i, pot := range h.Pots {
+		winners := make([]Winner, len(pot.Winners))
+		for j, w := range pot.Winners {
+			w.UserID = alias(w.UserID)
+			winners[j] = w
+		}
+		pot.Winners = winners
+		pots[i] = pot
+	}
+	h.Pots = pots
+	return h
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	_ "poker/internal/modules/history/dto"
+	"poker/internal/modules/history/export"
+	"poker/internal/modules/history/service"
+	"time"
+)
+
+type HistoryHandler struct {
+	service *service.HistoryService
+	logger  *zap.Logger
+}
+
+func NewHistoryHandler(service *service.HistoryService, logger *zap.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListHands godoc
+// @Summary Список сыгранных раздач
+// @Description Возвращает раздачи, в которых участвовал игрок, от новых к старым
+// @Tags History
+// @Produce json
+// @Param limit query int false "Сколько раздач вернуть (по умолчанию 20, не больше 100)"
+// @Param offset query int false "Сколько раздач пропустить"
+// @Success 200 {object} dto.HandList
+// @Failure 500 {object} map[string]string
+// @Router /history/hands [get]
+// @Security BearerAuth
+func (h *HistoryHandler) ListHands(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	list, err := h.service.ListHands(userID, c.QueryInt("limit"), c.QueryInt("offset"))
+	if err != nil {
+		h.logger.Error("❌ Failed to list hands", zap.String("userID", userID), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to list hands",
+		})
+	}
+	return c.JSON(list)
+}
+
+// GetHand godoc
+// @Summary История раздачи
+// @Description Места, стартовые стеки, карты, борд, все действия по улицам, банки и выплаты. Чужие закрытые карты видны, только если их показали на вскрытии
+// @Tags History
+// @Produce json
+// @Param id path string true "ID раздачи"
+// @Success 200 {object} dto.HandDetails
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /history/hands/{id} [get]
+// @Security BearerAuth
+func (h *HistoryHandler) GetHand(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	id := c.Params("id")
+
+	details, err := h.service.GetHand(userID, id)
+	if errors.Is(err, service.ErrHandNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error("❌ Failed to get hand", zap.String("id", id), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get hand",
+		})
+	}
+	return c.JSON(details)
+}
+
+// Replay godoc
+// @Summary Пошаговый повтор раздачи
+// @Description Кадры стола после раздачи карт и после каждого действия: стеки, ставки, банк, борд и чей ход. Ключи state те же, что в update-game-state. Чужие закрытые карты видны только в последнем кадре и только если их показали на вскрытии
+// @Tags History
+// @Produce json
+// @Param id path string true "ID раздачи"
+// @Success 200 {object} dto.HandReplay
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /history/hands/{id}/replay [get]
+// @Security BearerAuth
+func (h *HistoryHandler) Replay(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	id := c.Params("id")
+
+	replay, err := h.service.Replay(userID, id)
+	if errors.Is(err, service.ErrHandNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error("❌ Failed to get replay", zap.String("id", id), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get replay",
+		})
+	}
+	return c.JSON(replay)
+}
+
+// Export godoc
+// @Summary Выгрузка истории раздач
+// @Description Одна раздача (hand) или все раздачи за период (from..to включительно, UTC) текстом в формате PokerStars или в JSON по схеме /history/export/schema. Свои карты видны всегда, чужие — только показанные на вскрытии
+// @Tags History
+// @Produce plain
+// @Produce json
+// @Param format query string false "pokerstars (по умолчанию) или json"
+// @Param hand query string false "ID раздачи"
+// @Param from query string false "Первый день периода, YYYY-MM-DD; обязателен без hand"
+// @Param to query string false "Последний день периода, YYYY-MM-DD; по умолчанию сегодня"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /history/export [get]
+// @Security BearerAuth
+func (h *HistoryHandler) Export(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var hands []export.Hand
+	name := "hands"
+	if id := c.Query("hand"); id != "" {
+		hands, err = h.service.ExportHand(userID, id)
+		name = "hand-" + id
+	} else {
+		if c.Query("from") == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "hand or from is required",
+			})
+		}
+		from, to, rangeErr := export.ParseRange(c.Query("from"), c.Query("to"), time.Now())
+		if rangeErr != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": rangeErr.Error(),
+			})
+		}
+		hands, err = h.service.ExportRange(userID, from, to)
+		name = fmt.Sprintf("hands-%s-%s", from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"))
+	}
+
+	switch {
+	case errors.Is(err, service.ErrHandNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidRange), errors.Is(err, service.ErrTooManyHands):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		h.logger.Error("❌ Failed to export hands", zap.String("userID", userID), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to export hands",
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := format.Write(&buf, userID, hands); err != nil {
+		h.logger.Error("❌ Failed to render hands", zap.String("userID", userID), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to export hands",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Attachment(fmt.Sprintf("%s.%s", sanitize(name), format.Extension()))
+	return c.Send(buf.Bytes())
+}
+
+// ExportSchema godoc
+// @Summary JSON Schema выгрузки
+// @Description Схема JSON-формата /history/export?format=json
+// @Tags History
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /history/export/schema [get]
+func (h *HistoryHandler) ExportSchema(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, "application/schema+json")
+	return c.Send(export.Schema)
+}
+
+// sanitize — имя файла без символов, которые ломают Content-Disposition
+func sanitize(name string) string {
+	b := []byte(name)
+	for i, ch := range b {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"poker/internal/modules/history/dto"
+	"poker/internal/modules/history/export"
+	"poker/internal/modules/history/service"
+)
+
+// Share godoc
+// @Summary Опубликовать раздачу
+// @Description Создаёт публичную ссылку на раздачу, в которой участвовал игрок. Без разрешения владельца ники остальных игроков и сброшенные карты скрыты. Повторный вызов меняет настройки уже выданной ссылки
+// @Tags History
+// @Accept json
+// @Produce json
+// @Param id path string true "ID раздачи"
+// @Param request body dto.ShareRequest false "Что открыть"
+// @Success 200 {object} dto.HandShare
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /history/hands/{id}/share [post]
+// @Security BearerAuth
+func (h *HistoryHandler) Share(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	id := c.Params("id")
+
+	var req dto.ShareRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	share, err := h.service.Share(userID, id, req)
+	if errors.Is(err, service.ErrHandNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error("❌ Failed to share hand", zap.String("id", id), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to share hand",
+		})
+	}
+	return c.JSON(share)
+}
+
+// ListShares godoc
+// @Summary Мои публичные ссылки
+// @Description Действующие ссылки на раздачи, опубликованные игроком
+// @Tags History
+// @Produce json
+// @Success 200 {array} dto.HandShare
+// @Failure 500 {object} map[string]string
+// @Router /history/shares [get]
+// @Security BearerAuth
+func (h *HistoryHandler) ListShares(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	shares, err := h.service.ListShares(userID)
+	if err != nil {
+		h.logger.Error("❌ Failed to list shares", zap.String("userID", userID), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to list shares",
+		})
+	}
+	return c.JSON(shares)
+}
+
+// RevokeShare godoc
+// @Summary Отозвать ссылку
+// @Description После отзыва раздача по ссылке больше не открывается
+// @Tags History
+// @Param token path string true "Токен ссылки"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /history/shares/{token} [delete]
+// @Security BearerAuth
+func (h *HistoryHandler) RevokeShare(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	err := h.service.RevokeShare(userID, c.Params("token"))
+	if errors.Is(err, service.ErrShareNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error("❌ Failed to revoke share", zap.String("userID", userID), zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to revoke share",
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SharedHand godoc
+// @Summary Раздача по публичной ссылке
+// @Description Открывается без авторизации. Игроки обозначены местами, карты — не больше, чем видел опубликовавший. format=pokerstars отдаёт текст истории PokerStars
+// @Tags History
+// @Produce json
+// @Produce plain
+// @Param token path string true "Токен ссылки"
+// @Param format query string false "json (по умолчанию) или pokerstars"
+// @Success 200 {object} export.Hand
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /shared/hands/{token} [get]
+func (h *HistoryHandler) SharedHand(c *fiber.Ctx) error {
+	hand, err := h.service.SharedHand(c.Params("token"))
+	if errors.Is(err, service.ErrShareNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error("❌ Failed to get shared hand", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to get shared hand",
+		})
+	}
+
+	switch export.Format(c.Query("format", string(export.FormatJSON))) {
+	case export.FormatJSON:
+		return c.JSON(hand)
+	case export.FormatPokerStars:
+		var buf bytes.Buffer
+		if err := export.WritePokerStars(&buf, []export.Hand{hand}); err != nil {
+			h.logger.Error("❌ Failed to render shared hand", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to get shared hand",
+			})
+		}
+		c.Set(fiber.HeaderContentType, export.FormatPokerStars.ContentType())
+		return c.Send(buf.Bytes())
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "format must be json or pokerstars",
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"poker/packages/database"
+)
+
+type HistoryRepo struct {
+	db *gorm.DB
+}
+
+type HistoryRepoI interface {
+	SaveHand(session database.GameSession) error
+	ListHands(userID string, limit, offset int) ([]database.GameSession, int64, error)
+	GetHand(id string) (database.GameSession, error)
+	GetReplay(id string) (database.GameSession, error)
+	ExportHands(userID string, from, to int64, limit int) ([]database.GameSession, error)
+	ActiveShare(ownerID, gameID string) (database.HandShare, error)
+	SaveShare(share database.HandShare) error
+	GetShare(token string) (database.HandShare, error)
+	ListShares(ownerID string) ([]database.HandShare, error)
+	RevokeShare(ownerID, token string) (bool, error)
+}
+
+func NewHistoryRepo(db *gorm.DB) *HistoryRepo {
+	return &HistoryRepo{
+		db: db,
+	}
+}
+
+// SaveHand сохраняет раздачу с участниками, действиями и кадрами повтора одной транзакцией.
+// Уже сохранённая раздача не перезаписывается — ретрай активности безопасен
+func (r *HistoryRepo) SaveHand(session database.GameSession) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&database.GameSession{}).Where("id = ?", session.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Omit(clause.Associations).Create(&session).Error; err != nil {
+			return err
+		}
+		if len(session.Players) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&session.Players).Error; err != nil {
+				return err
+			}
+		}
+		if len(session.Moves) > 0 {
+			if err := tx.Create(&session.Moves).Error; err != nil {
+				return err
+			}
+		}
+		if len(session.Snapshots) > 0 {
+			if err := tx.Create(&session.Snapshots).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListHands — раздачи, в которых участвовал игрок, от новых к старым, с его строкой участника
+func (r *HistoryRepo) ListHands(userID string, limit, offset int) ([]database.GameSession, int64, error) {
+	query := r.db.Model(&database.GameSession{}).
+		Where("id IN (?)", r.db.Model(&database.GamePlayer{}).Select("game_id").Where("user_id = ?", userID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var sessions []database.GameSession
+	err := query.
+		Preload("Players", "user_id = ?", userID).
+		Order("started_at DESC, round DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessions).Error
+	return sessions, total, err
+}
+
+// GetHand — раздача со всеми участниками и действиями по порядку
+func (r *HistoryRepo) GetHand(id string) (database.GameSession, error) {
+	var session database.GameSession
+	err := r.full().Where("id = ?", id).First(&session).Error
+	return session, err
+}
+
+// GetReplay — раздача вместе с кадрами повтора по порядку
+func (r *HistoryRepo) GetReplay(id string) (database.GameSession, error) {
+	var session database.GameSession
+	err := r.full().
+		Preload("Snapshots", func(db *gorm.DB) *gorm.DB { return db.Order("seq") }).
+		Where("id = ?", id).
+		First(&session).Error
+	return session, err
+}
+
+// ExportHands — полные раздачи игрока, начатые в [from, to) по unix-времени, от старых к новым
+func (r *HistoryRepo) ExportHands(userID string, from, to int64, limit int) ([]database.GameSession, error) {
+	var sessions []database.GameSession
+	err := r.full().
+		Where("id IN (?)", r.db.Model(&database.GamePlayer{}).Select("game_id").Where("user_id = ?", userID)).
+		Where("started_at >= ? AND started_at < ?", from, to).
+		Order("started_at, round").
+		Limit(limit).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// full — запрос раздачи с участниками по местам и действиями по порядку
+func (r *HistoryRepo) full() *gorm.DB {
+	return r.db.
+		Preload("Players", func(db *gorm.DB) *gorm.DB { return db.Order("seat_number") }).
+		Preload("Players.User").
+		Preload("Moves", func(db *gorm.DB) *gorm.DB { return db.Order("seq") })
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"poker/packages/database"
+	"time"
+)
+
+// ActiveShare — действующая ссылка владельца на раздачу; пустой Token — ссылки нет
+func (r *HistoryRepo) ActiveShare(ownerID, gameID string) (database.HandShare, error) {
+	var share database.HandShare
+	err := r.db.
+		Where("owner_id = ? AND game_id = ? AND revoked_at = 0", ownerID, gameID).
+		Limit(1).
+		Find(&share).Error
+	return share, err
+}
+
+func (r *HistoryRepo) SaveShare(share database.HandShare) error {
+	return r.db.Save(&share).Error
+}
+
+// GetShare — действующая ссылка по токену
+func (r *HistoryRepo) GetShare(token string) (database.HandShare, error) {
+	var share database.HandShare
+	err := r.db.Where("token = ? AND revoked_at = 0", token).First(&share).Error
+	return share, err
+}
+
+// ListShares — действующие ссылки владельца, от новых к старым
+func (r *HistoryRepo) ListShares(ownerID string) ([]database.HandShare, error) {
+	var shares []database.HandShare
+	err := r.db.
+		Where("owner_id = ? AND revoked_at = 0", ownerID).
+		Order("created_at DESC").
+		Find(&shares).Error
+	return shares, err
+}
+
+// RevokeShare отзывает ссылку владельца; false — такой действующей ссылки нет
+func (r *HistoryRepo) RevokeShare(ownerID, token string) (bool, error) {
+	res := r.db.Model(&database.HandShare{}).
+		Where("token = ? AND owner_id = ? AND revoked_at = 0", token, ownerID).
+		Update("revoked_at", time.Now().Unix())
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,33 @@
+package history
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"os"
+	"poker/internal/middleware"
+	"poker/internal/modules/history/handler"
+	"poker/internal/modules/history/repo"
+	"poker/internal/modules/history/service"
+)
+
+func RegisterRoutes(router fiber.Router, db *gorm.DB, logger *zap.Logger) {
+	historyRepo := repo.NewHistoryRepo(db)
+	historyService := service.NewHistoryService(historyRepo, logger)
+	historyHandler := handler.NewHistoryHandler(historyService, logger)
+
+	// Схема публичная — её читают трекеры и конвертеры без токена
+	router.Get("/history/export/schema", historyHandler.ExportSchema)
+	// Опубликованные раздачи открывает любой, у кого есть ссылка
+	router.Get("/shared/hands/:token", historyHandler.SharedHand)
+
+	historyGroup := router.Group("/history")
+	historyGroup.Use(middleware.JWTAuthMiddleware(os.Getenv("JWT_KEY")))
+	historyGroup.Get("/hands", historyHandler.ListHands)
+	historyGroup.Get("/hands/:id", historyHandler.GetHand)
+	historyGroup.Get("/hands/:id/replay", historyHandler.Replay)
+	historyGroup.Post("/hands/:id/share", historyHandler.Share)
+	historyGroup.Get("/shares", historyHandler.ListShares)
+	historyGroup.Delete("/shares/:token", historyHandler.RevokeShare)
+	historyGroup.Get("/export", historyHandler.Export)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"poker/internal/modules/history/dto"
+	"poker/internal/modules/history/export"
+	"poker/internal/modules/history/repo"
+	"poker/packages/database"
+	"time"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// maxExportHands — больше раздач за одну выгрузку не отдаём
+	maxExportHands = 1000
+)
+
+var (
+	// ErrHandNotFound — раздачи нет или игрок в ней не участвовал
+	ErrHandNotFound = errors.New("hand not found")
+	ErrInvalidRange = errors.New("invalid date range: from must be before to")
+	ErrTooManyHands = fmt.Errorf("too many hands: at most %d per export, narrow the date range", maxExportHands)
+)
+
+type HistoryService struct {
+	repo   *repo.HistoryRepo
+	logger *zap.Logger
+}
+
+type HistoryServiceI interface {
+	ListHands(userID string, limit, offset int) (dto.HandList, error)
+	GetHand(userID, id string) (dto.HandDetails, error)
+	Replay(userID, id string) (dto.HandReplay, error)
+	ExportHand(userID, id string) ([]export.Hand, error)
+	ExportRange(userID string, from, to time.Time) ([]export.Hand, error)
+	Share(userID, id string, req dto.ShareRequest) (dto.HandShare, error)
+	ListShares(userID string) ([]dto.HandShare, error)
+	RevokeShare(userID, token string) error
+	SharedHand(token string) (export.Hand, error)
+}
+
+func NewHistoryService(repo *repo.HistoryRepo, logger *zap.Logger) *HistoryService {
+	return &HistoryService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *HistoryService) ListHands(userID string, limit, offset int) (dto.HandList, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	sessions, total, err := s.repo.ListHands(userID, limit, offset)
+	if err != nil {
+		return dto.HandList{}, err
+	}
+
+	list := dto.HandList{Hands: make([]dto.HandSummary, 0, len(sessions)), Total: total, Limit: limit, Offset: offset}
+	for _, session := range sessions {
+		list.Hands = append(list.Hands, summary(session, userID))
+	}
+	return list, nil
+}
+
+func (s *HistoryService) GetHand(userID, id string) (dto.HandDetails, error) {
+	session, err := s.hand(userID, id)
+	if err != nil {
+		return dto.HandDetails{}, err
+	}
+
+	details := dto.HandDetails{
+		HandSummary: summary(session, userID),
+		Dealer:      session.Dealer,
+		Results:     json.RawMessage(session.Results),
+	}
+	if len(details.Results) == 0 {
+		details.Results = json.RawMessage("[]")
+	}
+
+	details.Players = handPlayers(session, userID)
+	for _, m := range session.Moves {
+		details.Actions = append(details.Actions, handAction(m))
+	}
+	return details, nil
+}
+
+// Replay — раздача по кадрам глазами userID: свои карты видны во всех кадрах,
+// чужие — только в последнем и только если их показали на вскрытии
+func (s *HistoryService) Replay(userID, id string) (dto.HandReplay, error) {
+	session, err := s.repo.GetReplay(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !tookPart(session, userID)) {
+		return dto.HandReplay{}, ErrHandNotFound
+	}
+	if err != nil {
+		return dto.HandReplay{}, err
+	}
+
+	replay := dto.HandReplay{
+		HandSummary: summary(session, userID),
+		Dealer:      session.Dealer,
+		Players:     handPlayers(session, userID),
+		Frames:      make([]dto.ReplayFrame, 0, len(session.Snapshots)),
+	}
+
+	moves := make(map[int]database.GameMove, len(session.Moves))
+	for _, m := range session.Moves {
+		moves[m.Seq] = m
+	}
+	visible := make(map[string]bool, len(session.Players))
+	for _, p := range session.Players {
+		visible[p.UserID] = export.CardsVisible(session, p, userID)
+	}
+
+	lastAction := 0
+	for i, snap := range session.Snapshots {
+		var state map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(snap.State), &state); err != nil {
+			return dto.HandReplay{}, fmt.Errorf("hand %s: invalid replay frame %d: %w", id, snap.Seq, err)
+		}
+		var hidden map[string]json.RawMessage
+		if snap.Cards != "" {
+			if err := json.Unmarshal([]byte(snap.Cards), &hidden); err != nil {
+				return dto.HandReplay{}, fmt.Errorf("hand %s: invalid replay cards %d: %w", id, snap.Seq, err)
+			}
+		}
+
+		last := i == len(session.Snapshots)-1
+		playerCards := make(map[string]json.RawMessage)
+		for owner, cs := range hidden {
+			if owner == userID || (last && visible[owner]) {
+				playerCards[owner] = cs
+			}
+		}
+		if state["playerCards"], err = json.Marshal(playerCards); err != nil {
+			return dto.HandReplay{}, err
+		}
+		raw, err := json.Marshal(state)
+		if err != nil {
+			return dto.HandReplay{}, err
+		}
+
+		frame := dto.ReplayFrame{Seq: snap.Seq, Street: snap.Street, State: raw, At: snap.CreatedAt}
+		// Кадр раздачи карт и кадры без нового действия (докладка борда) — без действия
+		if i > 0 && snap.ActionSeq > lastAction {
+			if m, ok := moves[snap.ActionSeq]; ok {
+				action := handAction(m)
+				frame.Action = &action
+			}
+		}
+		lastAction = snap.ActionSeq
+		replay.Frames = append(replay.Frames, frame)
+	}
+	return replay, nil
+}
+
+// ExportHand — одна раздача для выгрузки глазами userID
+func (s *HistoryService) ExportHand(userID, id string) ([]export.Hand, error) {
+	session, err := s.hand(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	h, err := export.FromSession(session, userID)
+	if err != nil {
+		return nil, err
+	}
+	return []export.Hand{h}, nil
+}
+
+// ExportRange — раздачи игрока, начатые в [from, to), от старых к новым
+func (s *HistoryService) ExportRange(userID string, from, to time.Time) ([]export.Hand, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	sessions, err := s.repo.ExportHands(userID, from.Unix(), to.Unix(), maxExportHands+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) > maxExportHands {
+		return nil, ErrTooManyHands
+	}
+
+	hands := make([]export.Hand, 0, len(sessions))
+	for _, session := range sessions {
+		h, err := export.FromSession(session, userID)
+		if err != nil {
+			return nil, err
+		}
+		hands = append(hands, h)
+	}
+	return hands, nil
+}
+
+// hand — полная раздача, если userID в ней участвовал
+func (s *HistoryService) hand(userID, id string) (database.GameSession, error) {
+	session, err := s.repo.GetHand(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, ErrHandNotFound
+	}
+	if err != nil {
+		return session, err
+	}
+	if !tookPart(session, userID) {
+		return session, ErrHandNotFound
+	}
+	return session, nil
+}
+
+// handPlayers — участники раздачи; чужие закрытые карты — только показанные на вскрытии
+func handPlayers(session database.GameSession, userID string) []dto.HandPlayer {
+	players := make([]dto.HandPlayer, 0, len(session.Players))
+	for _, p := range session.Players {
+		player := dto.HandPlayer{
+			UserID:   p.UserID,
+			Nickname: p.User.Username,
+			Seat:     p.SeatNumber,
+			Stack:    p.Chips,
+			UpCards:  p.UpCards,
+			Folded:   p.IsFolded,
+			AllIn:    p.IsAllIn,
+			Won:      p.Won,
+			Hand:     p.Hand,
+		}
+		if export.CardsVisible(session, p, userID) {
+			player.Cards = p.HoleCards
+		}
+		players = append(players, player)
+	}
+	return players
+}
+
+func handAction(m database.GameMove) dto.HandAction {
+	return dto.HandAction{
+		Seq:       m.Seq,
+		Street:    m.Street,
+		UserID:    m.PlayerID,
+		Action:    m.Action,
+		Amount:    m.Amount,
+		Total:     m.Total,
+		AllIn:     m.IsAllIn,
+		Discarded: m.Discarded,
+		At:        m.CreatedAt,
+	}
+}
+
+// summary — строка списка; карты и выигрыш — того, кто смотрит историю
+func summary(session database.GameSession, userID string) dto.HandSummary {
+	s := dto.HandSummary{
+		ID:               session.ID,
+		RoomID:           session.RoomID,
+		Hand:             session.Round,
+		GameType:         session.GameType,
+		BettingStructure: session.BettingStructure,
+		Blinds:           session.Blinds,
+		Pot:              session.Pot,
+		Rake:             session.Rake,
+		Street:           session.Status,
+		Board:            session.Board,
+		StartedAt:        session.StartedAt,
+	}
+	for _, p := range session.Players {
+		if p.UserID == userID {
+			s.Cards = p.HoleCards
+			s.Won = p.Won
+		}
+	}
+	return s
+}
+
+func tookPart(session database.GameSession, userID string) bool {
+	for _, p := range session.Players {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"poker/internal/modules/history/dto"
+	"poker/internal/modules/history/export"
+	"poker/packages/database"
+	"time"
+)
+
+// sharePath — публичный адрес раздачи, открывается без JWT
+const sharePath = "/shared/hands/"
+
+// ErrShareNotFound — ссылки нет, она отозвана или принадлежит другому игроку
+var ErrShareNotFound = errors.New("share not found")
+
+// Share публикует раздачу, в которой участвовал userID. У раздачи одна действующая
+// ссылка на владельца: повторная публикация меняет только настройки
+func (s *HistoryService) Share(userID, id string, req dto.ShareRequest) (dto.HandShare, error) {
+	if _, err := s.hand(userID, id); err != nil {
+		return dto.HandShare{}, err
+	}
+
+	share, err := s.repo.ActiveShare(userID, id)
+	if err != nil {
+		return dto.HandShare{}, err
+	}
+	if share.Token == "" {
+		token, err := newShareToken()
+		if err != nil {
+			return dto.HandShare{}, err
+		}
+		share = database.HandShare{Token: token, GameID: id, OwnerID: userID, CreatedAt: time.Now().Unix()}
+	}
+	share.ShowNames = req.ShowNames
+	share.ShowFoldedCards = req.ShowFoldedCards
+
+	if err := s.repo.SaveShare(share); err != nil {
+		return dto.HandShare{}, err
+	}
+	return shareDTO(share), nil
+}
+
+func (s *HistoryService) ListShares(userID string) ([]dto.HandShare, error) {
+	shares, err := s.repo.ListShares(userID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]dto.HandShare, 0, len(shares))
+	for _, share := range shares {
+		list = append(list, shareDTO(share))
+	}
+	return list, nil
+}
+
+// RevokeShare отзывает ссылку; открыть раздачу по ней больше нельзя
+func (s *HistoryService) RevokeShare(userID, token string) error {
+	revoked, err := s.repo.RevokeShare(userID, token)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// SharedHand — раздача по публичной ссылке, скрытая по настройкам владельца
+func (s *HistoryService) SharedHand(token string) (export.Hand, error) {
+	share, err := s.repo.GetShare(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return export.Hand{}, ErrShareNotFound
+	}
+	if err != nil {
+		return export.Hand{}, err
+	}
+
+	session, err := s.repo.GetHand(share.GameID)
+	if err != nil {
+		return export.Hand{}, err
+	}
+	h, err := export.FromSession(session, share.OwnerID)
+	if err != nil {
+		return export.Hand{}, err
+	}
+	return export.Public(h, export.PublicOptions{
+		ShowNames:       share.ShowNames,
+		ShowFoldedCards: share.ShowFoldedCards,
+	}), nil
+}
+
+// newShareToken — 256 случайных бит: ссылку нельзя подобрать перебором
+func newShareToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func shareDTO(share database.HandShare) dto.HandShare {
+	return dto.HandShare{
+		Token:           share.Token,
+		HandID:          share.GameID,
+		Path:            sharePath + share.Token,
+		ShowNames:       share.ShowNames,
+		ShowFoldedCards: share.ShowFoldedCards,
+		CreatedAt:       share.CreatedAt,
+	}
+}
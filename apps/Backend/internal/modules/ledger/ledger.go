@@ -0,0 +1,255 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"poker/packages/database"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reason — зачем фишки сменили счёт
+type Reason string
+
+const (
+	ReasonBuyIn           Reason = "buy_in"
+	ReasonCashOut         Reason = "cash_out"
+	ReasonWin             Reason = "win"
+	ReasonRake            Reason = "rake"
+	ReasonDailyReward     Reason = "daily_reward"
+	ReasonAdminAdjustment Reason = "admin_adjustment"
+)
+
+var reasons = map[Reason]bool{
+	ReasonBuyIn:           true,
+	ReasonCashOut:         true,
+	ReasonWin:             true,
+	ReasonRake:            true,
+	ReasonDailyReward:     true,
+	ReasonAdminAdjustment: true,
+}
+
+// Виды счетов. Системные счета — источники фишек и могут уходить в минус,
+// остальные — нет. Сумма балансов всех счетов всегда равна нулю
+const (
+	KindWallet = "wallet"
+	KindTable  = "table"
+	KindHouse  = "house"
+	KindSystem = "system"
+)
+
+// Постоянные счета
+const (
+	House       = "house"              // рейк
+	Rewards     = "system:rewards"     // ежедневные награды
+	Adjustments = "system:adjustments" // ручные исправления администратора
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Wallet — кошелёк игрока
+func Wallet(userID string) string {
+	return KindWallet + ":" + userID
+}
+
+// Table — фишки игрока на столе
+func Table(roomID, userID string) string {
+	return KindTable + ":" + roomID + ":" + userID
+}
+
+// Leg — изменение одного счёта в операции
+type Leg struct {
+	Account string
+	Amount  int64
+}
+
+// Posting — что за операция: причина, ссылка и ключ идемпотентности.
+// Операция с уже проведённым ключом второй раз не проводится
+type Posting struct {
+	Reason    Reason
+	Reference string
+	Key       string // пустой — без защиты от повтора
+}
+
+// Transfer — amount со счёта from на счёт to
+func Transfer(tx *gorm.DB, p Posting, from, to string, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("transfer amount must be positive, got %d", amount)
+	}
+	return Post(tx, p, Leg{Account: from, Amount: -amount}, Leg{Account: to, Amount: amount})
+}
+
+// Post проводит операцию внутри транзакции tx: блокирует счета, проверяет, что ни один
+// несистемный счёт не уходит в минус, дописывает проводки и обновляет балансы.
+// Повтор с тем же ключом возвращает ID уже проведённой операции
+func Post(tx *gorm.DB, p Posting, legs ...Leg) (string, error) {
+	reason := p.Reason
+	if !reasons[reason] {
+		return "", fmt.Errorf("unknown ledger reason %q", reason)
+	}
+
+	// Несколько ног по одному счёту сводятся в одну
+	amounts := make(map[string]int64)
+	var sum int64
+	for _, l := range legs {
+		amounts[l.Account] += l.Amount
+		sum += l.Amount
+	}
+	if sum != 0 {
+		return "", fmt.Errorf("unbalanced %s transaction: legs sum to %d", reason, sum)
+	}
+	accounts := make([]string, 0, len(amounts))
+	for id, amount := range amounts {
+		if amount != 0 {
+			accounts = append(accounts, id)
+		}
+	}
+	if id, done, err := Lookup(tx, p.Key); err != nil || done {
+		return id, err
+	}
+	if len(accounts) == 0 {
+		return "", nil
+	}
+	// Один порядок блокировок во всех операциях — без взаимных блокировок
+	sort.Strings(accounts)
+
+	now := time.Now().Unix()
+	txn := database.LedgerTransaction{
+		ID:             uuid.New().String(),
+		Reason:         string(reason),
+		Reference:      p.Reference,
+		IdempotencyKey: p.Key,
+		CreatedAt:      now,
+	}
+	for _, id := range accounts {
+		account, err := lockAccount(tx, id)
+		if err != nil {
+			return "", err
+		}
+		balance := account.Balance + amounts[id]
+		if balance < 0 && account.Kind != KindSystem {
+			return "", fmt.Errorf("%w on %s: balance=%d, change=%d", ErrInsufficientFunds, id, account.Balance, amounts[id])
+		}
+		if err := setBalance(tx, account, balance, now); err != nil {
+			return "", err
+		}
+		txn.Entries = append(txn.Entries, database.LedgerEntry{
+			ID:           uuid.New().String(),
+			AccountID:    id,
+			Amount:       amounts[id],
+			BalanceAfter: balance,
+			CreatedAt:    now,
+		})
+	}
+
+	if err := tx.Create(&txn).Error; err != nil {
+		return "", fmt.Errorf("failed to record %s transaction: %w", reason, err)
+	}
+	return txn.ID, nil
+}
+
+// Adjust доводит баланс счёта до target проводкой со счёта Adjustments
+func Adjust(tx *gorm.DB, accountID string, target int64, reference string) error {
+	if target < 0 {
+		return fmt.Errorf("balance must not be negative, got %d", target)
+	}
+	account, err := lockAccount(tx, accountID)
+	if err != nil {
+		return err
+	}
+	delta := target - account.Balance
+	_, err = Post(tx, Posting{Reason: ReasonAdminAdjustment, Reference: reference},
+		Leg{Account: Adjustments, Amount: -delta},
+		Leg{Account: accountID, Amount: delta},
+	)
+	return err
+}
+
+// Lookup ищет операцию, уже проведённую с ключом key
+func Lookup(tx *gorm.DB, key string) (string, bool, error) {
+	if key == "" {
+		return "", false, nil
+	}
+	var txn database.LedgerTransaction
+	err := tx.Where("idempotency_key = ?", key).Limit(1).Find(&txn).Error
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up idempotency key %s: %w", key, err)
+	}
+	return txn.ID, txn.ID != "", nil
+}
+
+// Balance — текущий баланс счёта; у неоткрытого счёта он нулевой
+func Balance(db *gorm.DB, accountID string) (int64, error) {
+	var account database.LedgerAccount
+	err := db.Where("id = ?", accountID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return account.Balance, err
+}
+
+// lockAccount открывает счёт при первом обращении и блокирует его строку до конца транзакции
+func lockAccount(tx *gorm.DB, id string) (database.LedgerAccount, error) {
+	kind, owner := parseAccount(id)
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.LedgerAccount{
+		ID:        id,
+		Kind:      kind,
+		OwnerID:   owner,
+		UpdatedAt: time.Now().Unix(),
+	}).Error
+	if err != nil {
+		return database.LedgerAccount{}, fmt.Errorf("failed to open account %s: %w", id, err)
+	}
+
+	var account database.LedgerAccount
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&account).Error
+	if err != nil {
+		return account, fmt.Errorf("failed to lock account %s: %w", id, err)
+	}
+	return account, nil
+}
+
+func setBalance(tx *gorm.DB, account database.LedgerAccount, balance, now int64) error {
+	err := tx.Model(&database.LedgerAccount{}).
+		Where("id = ?", account.ID).
+		Updates(map[string]interface{}{"balance": balance, "updated_at": now}).Error
+	if err != nil {
+		return err
+	}
+	if account.Kind != KindWallet {
+		return nil
+	}
+
+	// Баланс кошелька, который видят игроки, — проекция того же счёта
+	res := tx.Model(&database.AccountBalance{}).
+		Where("user_id = ?", account.OwnerID).
+		Update("current_balance", balance)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("account balance not found for user %s", account.OwnerID)
+	}
+	return nil
+}
+
+// parseAccount — вид и владелец счёта по его ID
+func parseAccount(id string) (kind, owner string) {
+	kind, rest, _ := strings.Cut(id, ":")
+	switch kind {
+	case KindWallet:
+		return kind, rest
+	case KindTable:
+		// table:<room>:<user> — счёт принадлежит игроку
+		_, user, _ := strings.Cut(rest, ":")
+		return kind, user
+	case KindHouse:
+		return kind, ""
+	default:
+		return KindSystem, ""
+	}
+}
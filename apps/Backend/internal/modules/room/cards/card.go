@@ -0,0 +1,165 @@
+package cards
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Rank — достоинство карты, от двойки до туза
+type Rank uint8
+
+const (
+	Two Rank = iota
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+)
+
+// Suit — масть карты
+type Suit uint8
+
+const (
+	Spades Suit = iota
+	Hearts
+	Diamonds
+	Clubs
+)
+
+const (
+	NumRanks = 13
+	NumSuits = 4
+)
+
+var rankNames = [NumRanks]string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}
+
+// rankLetters — однобуквенная запись ранга для нотации "Ts"
+var rankLetters = [NumRanks]string{"2", "3", "4", "5", "6", "7", "8", "9", "T", "J", "Q", "K", "A"}
+
+var suitSymbols = [NumSuits]string{"♠", "♥", "♦", "♣"}
+
+var suitLetters = [NumSuits]string{"S", "H", "D", "C"}
+
+func (r Rank) String() string { return rankNames[r] }
+
+func (s Suit) String() string { return suitSymbols[s] }
+
+// Card — карта в виде числа: rank*4 + suit
+type Card uint8
+
+func New(rank Rank, suit Suit) Card {
+	return Card(uint8(rank)*NumSuits + uint8(suit))
+}
+
+func (c Card) Rank() Rank { return Rank(c / NumSuits) }
+func (c Card) Suit() Suit { return Suit(c % NumSuits) }
+
+// Valid — карта из стандартной колоды в 52 карты
+func (c Card) Valid() bool { return c < NumRanks*NumSuits }
+
+// String — запись для игроков и протокола: "10♠"
+func (c Card) String() string {
+	return rankNames[c.Rank()] + suitSymbols[c.Suit()]
+}
+
+// Short — короткая запись: "Ts"
+func (c Card) Short() string {
+	return rankLetters[c.Rank()] + strings.ToLower(suitLetters[c.Suit()])
+}
+
+// ImageName — имя картинки карты на фронтенде: "10S"
+func (c Card) ImageName() string {
+	return rankNames[c.Rank()] + suitLetters[c.Suit()]
+}
+
+// Parse разбирает карту в любой из нотаций: "10♠", "Ts", "10S"
+func Parse(s string) (Card, error) {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) < 2 {
+		return 0, fmt.Errorf("invalid card %q", s)
+	}
+
+	var suit Suit
+	switch runes[len(runes)-1] {
+	case '♠', 's', 'S':
+		suit = Spades
+	case '♥', 'h', 'H':
+		suit = Hearts
+	case '♦', 'd', 'D':
+		suit = Diamonds
+	case '♣', 'c', 'C':
+		suit = Clubs
+	default:
+		return 0, fmt.Errorf("invalid card %q: unknown suit", s)
+	}
+
+	rank := strings.ToUpper(string(runes[:len(runes)-1]))
+	for r := range rankNames {
+		if rankNames[r] == rank || rankLetters[r] == rank {
+			return New(Rank(r), suit), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid card %q: unknown rank", s)
+}
+
+// MustParse — для констант и тестов; паникует на неверной записи
+func MustParse(s string) Card {
+	c, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ParseList разбирает несколько карт, разделённых пробелами или запятыми
+func ParseList(s string) ([]Card, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	out := make([]Card, 0, len(fields))
+	for _, f := range fields {
+		c, err := Parse(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// MarshalJSON — в протоколе карта передаётся строкой "10♠"
+func (c Card) MarshalJSON() ([]byte, error) {
+	if !c.Valid() {
+		return nil, fmt.Errorf("invalid card %d", uint8(c))
+	}
+	return json.Marshal(c.String())
+}
+
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// Join — карты через разделитель, для сообщений в чат
+func Join(cs []Card, sep string) string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, sep)
+}
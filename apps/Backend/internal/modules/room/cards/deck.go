@@ -0,0 +1,41 @@
+package cards
+
+import "poker/internal/modules/room/rng"
+
+// Deck — колода; карты сдаются с начала
+type Deck []Card
+
+// NewDeck — упорядоченная колода из 52 карт
+func NewDeck() Deck {
+	return NewDeckFrom(Two)
+}
+
+// NewDeckFrom — упорядоченная колода без карт младше minRank (шорт-дек — с шестёрки, 36 карт)
+func NewDeckFrom(minRank Rank) Deck {
+	d := make(Deck, 0, int(NumRanks-minRank)*NumSuits)
+	for s := Suit(0); s < NumSuits; s++ {
+		for r := minRank; r < NumRanks; r++ {
+			d = append(d, New(r, s))
+		}
+	}
+	return d
+}
+
+// Shuffle перемешивает колоду (Фишер — Йейтс): для i от последней карты к первой
+// меняются местами i и случайная карта из [0, i]
+func (d Deck) Shuffle(src rng.Source) {
+	for i := len(d) - 1; i > 0; i-- {
+		j := rng.Intn(src, i+1)
+		d[i], d[j] = d[j], d[i]
+	}
+}
+
+// Draw снимает n карт сверху колоды. Если карт не хватает, возвращает nil
+func (d *Deck) Draw(n int) []Card {
+	if len(*d) < n {
+		return nil
+	}
+	out := append([]Card(nil), (*d)[:n]...)
+	*d = (*d)[n:]
+	return out
+}
@@ -8,8 +8,25 @@ import (
 type CreateRoomRequest struct {
 	Name       string `json:"name"`
 	MaxPlayers int    `json:"max_players" validate:"required,min=2,max=10"`
-	Limits     string `json:"limits"` // Например, "1/2" или "5/10"
+	Limits     string `json:"limits"` // Например, "1/2" или "5/10"; третье число — анте: "5/10/1". В стаде — бринг-ин/малая ставка/анте
 	Type       string `json:"type"`   // "cash", "sitngo", "mtt"
+
+	MinBuyIn int64 `json:"min_buy_in"` // сколько фишек можно принести за стол; 0 — 20 больших блайндов
+	MaxBuyIn int64 `json:"max_buy_in"` // 0 — 100 больших блайндов
+
+	RakePercent float64 `json:"rake_percent"` // рейк кэш-стола: процент каждого банка, до 10; нет флопа — нет рейка
+	RakeCap     int64   `json:"rake_cap"`     // потолок рейка за раздачу, 0 — без потолка
+
+	GameType         string `json:"game_type"`         // "holdem" (по умолчанию), "omaha", "holdem-hilo", "omaha-hilo", "short-deck", "stud", "stud-hilo", "2-7-triple-draw"
+	BettingStructure string `json:"betting_structure"` // "no-limit", "pot-limit", "fixed-limit"; по умолчанию — как принято для game_type
+
+	ActionTimeout int `json:"action_timeout"` // секунд на ход, 0 — 30 секунд
+	TimeBank      int `json:"time_bank"`      // банк времени игрока в секундах, 0 — 60 секунд
+
+	// Смешанный стол: "hose" или список игр "holdem:fixed-limit,omaha-hilo,stud"; пусто — одна игра game_type
+	Rotation      string `json:"rotation"`
+	RotationHands int    `json:"rotation_hands"` // раздач на каждую игру, 0 — круг
+	DealersChoice bool   `json:"dealers_choice"` // следующую игру выбирает баттон (activity "choose-game")
 }
 
 type CreateRoomResponse struct {
@@ -32,6 +49,21 @@ type TerminateGameRequest struct {
 	RoomID string `json:"roomID" validate:"required"`
 }
 
+// VerifyShuffleRequest — данные раздачи из блока fairness, по которым колода восстанавливается заново
+type VerifyShuffleRequest struct {
+	ServerSeed  string            `json:"server_seed" validate:"required"`
+	Commitment  string            `json:"commitment" validate:"required"`
+	ClientSeeds map[string]string `json:"client_seeds"`
+	GameType    string            `json:"game_type"` // от игры зависит колода: в шорт-деке — с шестёрки
+}
+
+// VerifyShuffleResponse — порядок колоды сверху вниз
+type VerifyShuffleResponse struct {
+	Valid     bool     `json:"valid"`
+	Algorithm string   `json:"algorithm"`
+	Deck      []string `json:"deck"`
+}
+
 // AvailableRoomListResponse пример структуры ответа
 type AvailableRoomListResponse struct {
 	Rooms []database.Room `json:"rooms"`
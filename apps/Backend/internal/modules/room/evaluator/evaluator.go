@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"math/bits"
+	"poker/internal/modules/room/cards"
+)
+
+// Category — категория комбинации, от старшей карты до стрит-флеша
+type Category uint8
+
+const (
+	HighCard Category = iota + 1
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var categoryNames = map[Category]string{
+	HighCard:      "High Card",
+	OnePair:       "One Pair",
+	TwoPair:       "Two Pair",
+	ThreeOfAKind:  "Three of a Kind",
+	Straight:      "Straight",
+	Flush:         "Flush",
+	FullHouse:     "Full House",
+	FourOfAKind:   "Four of a Kind",
+	StraightFlush: "Straight Flush",
+}
+
+func (c Category) String() string {
+	return categoryNames[c]
+}
+
+// HandValue — сила комбинации одним числом: чем больше, тем сильнее.
+// Биты 24..27 — старшинство категории по правилам игры, 20..23 — сама категория,
+// ниже пять полубайтов — ранги для сравнения (кикеры),
+// поэтому две руки сравниваются обычным сравнением чисел.
+type HandValue uint32
+
+const (
+	categoryShift = 20
+	orderShift    = 24
+)
+
+func (v HandValue) Category() Category {
+	return Category(v >> categoryShift & 0xf)
+}
+
+func (v HandValue) String() string {
+	return v.Category().String()
+}
+
+// Compare возвращает 1, 0 или -1, как и раньше делал compareHands
+func Compare(a, b HandValue) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	}
+	return 0
+}
+
+func (e *Evaluator) makeValue(c Category, kickers uint32) HandValue {
+	return HandValue(uint32(e.order[c])<<orderShift | uint32(c)<<categoryShift | kickers)
+}
+
+// Evaluate возвращает силу лучшей пятикарточной комбинации по стандартным правилам
+func Evaluate(hand []cards.Card) HandValue {
+	return standard.Evaluate(hand)
+}
+
+// Evaluate возвращает силу лучшей пятикарточной комбинации. Карт может быть меньше пяти
+// (открытые карты в стаде) или больше семи (стад с общей картой)
+func (e *Evaluator) Evaluate(hand []cards.Card) HandValue {
+	var (
+		suitMasks [cards.NumSuits]uint16
+		counts    [cards.NumRanks]uint8
+		rankMask  uint16
+	)
+	for _, c := range hand {
+		suitMasks[c.Suit()] |= 1 << c.Rank()
+		counts[c.Rank()]++
+		rankMask |= 1 << c.Rank()
+	}
+
+	// Стрит-флеш старше всего. Остальные кандидаты сравниваются по значению,
+	// так что старшинство категорий задают правила (в шорт-деке флеш старше фулл-хауса)
+	var best HandValue
+	for _, mask := range suitMasks {
+		if bits.OnesCount16(mask) >= 5 {
+			if high := e.straightHigh[mask]; high != 0 {
+				return e.makeValue(StraightFlush, uint32(high)<<16)
+			}
+			best = e.makeValue(Flush, topFive[mask])
+		}
+	}
+	if high := e.straightHigh[rankMask]; high != 0 {
+		if v := e.makeValue(Straight, uint32(high)<<16); v > best {
+			best = v
+		}
+	}
+	if v := e.evaluateGroups(&counts, rankMask); v > best {
+		best = v
+	}
+	return best
+}
+
+// evaluateGroups — комбинации из одинаковых рангов: каре, фулл-хаус, сет, пары, старшая карта
+func (e *Evaluator) evaluateGroups(counts *[cards.NumRanks]uint8, rankMask uint16) HandValue {
+	quad, trips, pairs := -1, [2]int{-1, -1}, [3]int{-1, -1, -1}
+	nTrips, nPairs := 0, 0
+	for r := cards.NumRanks - 1; r >= 0; r-- {
+		switch counts[r] {
+		case 4:
+			quad = r
+		case 3:
+			if nTrips < 2 {
+				trips[nTrips] = r
+				nTrips++
+			}
+		case 2:
+			if nPairs < 3 {
+				pairs[nPairs] = r
+				nPairs++
+			}
+		}
+	}
+
+	switch {
+	case quad >= 0:
+		return e.makeValue(FourOfAKind, uint32(quad)<<16|kickers(rankMask&^(1<<quad), 1)<<12)
+	case nTrips > 0 && (nTrips > 1 || nPairs > 0):
+		pair := pairs[0]
+		if nTrips > 1 && trips[1] > pair {
+			pair = trips[1]
+		}
+		return e.makeValue(FullHouse, uint32(trips[0])<<16|uint32(pair)<<12)
+	case nTrips > 0:
+		return e.makeValue(ThreeOfAKind, uint32(trips[0])<<16|kickers(rankMask&^(1<<trips[0]), 2)<<8)
+	case nPairs > 1:
+		rest := rankMask &^ (1<<pairs[0] | 1<<pairs[1])
+		return e.makeValue(TwoPair, uint32(pairs[0])<<16|uint32(pairs[1])<<12|kickers(rest, 1)<<8)
+	case nPairs == 1:
+		return e.makeValue(OnePair, uint32(pairs[0])<<16|kickers(rankMask&^(1<<pairs[0]), 3)<<4)
+	}
+	return e.makeValue(HighCard, topFive[rankMask])
+}
+
+// kickers упаковывает n старших рангов из mask по полубайту, старший — первым
+func kickers(mask uint16, n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		v <<= 4
+		if mask == 0 {
+			continue
+		}
+		r := 15 - bits.LeadingZeros16(mask)
+		v |= uint32(r)
+		mask &^= 1 << r
+	}
+	return v
+}
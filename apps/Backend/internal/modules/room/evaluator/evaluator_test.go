@@ -0,0 +1,292 @@
+package evaluator
+
+import (
+	"math/rand"
+	"poker/internal/modules/room/cards"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// referenceRank — наивная оценка ровно пяти карт по правилам: ключ сравнения и категория
+func referenceRank(hand []cards.Card, rules Rules) (int, Category) {
+	var counts [cards.NumRanks]int
+	flush := true
+	for _, c := range hand {
+		counts[c.Rank()]++
+		if c.Suit() != hand[0].Suit() {
+			flush = false
+		}
+	}
+
+	// Ранги по убыванию: сначала большие группы, внутри группы — старшие
+	ranks := make([]int, 0, 5)
+	for r := cards.NumRanks - 1; r >= 0; r-- {
+		if counts[r] > 0 {
+			ranks = append(ranks, r)
+		}
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return counts[ranks[i]] > counts[ranks[j]] })
+
+	straightHigh := -1
+	if len(ranks) == 5 {
+		switch {
+		case ranks[0]-ranks[4] == 4:
+			straightHigh = ranks[0]
+		case !rules.NoWheel && ranks[0] == int(cards.Ace) &&
+			ranks[1] == int(rules.MinRank)+3 && ranks[4] == int(rules.MinRank):
+			straightHigh = int(rules.MinRank) + 3
+		}
+	}
+
+	var category Category
+	switch {
+	case straightHigh >= 0 && flush:
+		category = StraightFlush
+	case counts[ranks[0]] == 4:
+		category = FourOfAKind
+	case counts[ranks[0]] == 3 && counts[ranks[1]] == 2:
+		category = FullHouse
+	case flush:
+		category = Flush
+	case straightHigh >= 0:
+		category = Straight
+	case counts[ranks[0]] == 3:
+		category = ThreeOfAKind
+	case counts[ranks[0]] == 2 && counts[ranks[1]] == 2:
+		category = TwoPair
+	case counts[ranks[0]] == 2:
+		category = OnePair
+	default:
+		category = HighCard
+	}
+	if straightHigh >= 0 {
+		ranks = []int{straightHigh}
+	}
+
+	order := int(category)
+	if rules.FlushBeatsFullHouse {
+		switch category {
+		case Flush:
+			order = int(FullHouse)
+		case FullHouse:
+			order = int(Flush)
+		}
+	}
+	key := order
+	for i := 0; i < 5; i++ {
+		key <<= 4
+		if i < len(ranks) {
+			key |= ranks[i] + 1
+		}
+	}
+	return key, category
+}
+
+// referenceBest — лучшая пятёрка перебором всех сочетаний
+func referenceBest(hand []cards.Card, rules Rules) (int, Category) {
+	best, bestCategory := -1, Category(0)
+	five := make([]cards.Card, 5)
+	var pick func(start, n int)
+	pick = func(start, n int) {
+		if n == 5 {
+			if key, c := referenceRank(five, rules); key > best {
+				best, bestCategory = key, c
+			}
+			return
+		}
+		for i := start; i <= len(hand)-(5-n); i++ {
+			five[n] = hand[i]
+			pick(i+1, n+1)
+		}
+	}
+	pick(0, 0)
+	return best, bestCategory
+}
+
+func deck(minRank cards.Rank) []cards.Card {
+	var d []cards.Card
+	for r := minRank; r <= cards.Ace; r++ {
+		for s := cards.Spades; s <= cards.Clubs; s++ {
+			d = append(d, cards.New(r, s))
+		}
+	}
+	return d
+}
+
+func parseHand(t testing.TB, s string) []cards.Card {
+	t.Helper()
+	var hand []cards.Card
+	for _, f := range strings.Fields(s) {
+		c, err := cards.Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hand = append(hand, c)
+	}
+	return hand
+}
+
+type ruleSet struct {
+	name  string
+	rules Rules
+	eval  *Evaluator
+}
+
+var ruleSets = []ruleSet{
+	{"standard", StandardRules, standard},
+	{"short-deck", ShortDeckRules, shortDeck},
+	{"deuce-to-seven", DeuceToSevenRules, deuceToSeven},
+}
+
+// Все пятикарточные руки: оценщик должен упорядочить их так же, как перебор,
+// и совпасть с ним в категории
+func TestEvaluateAllFiveCardHands(t *testing.T) {
+	for _, rs := range ruleSets {
+		t.Run(rs.name, func(t *testing.T) {
+			d := deck(rs.rules.MinRank)
+			type scored struct {
+				value HandValue
+				key   int
+			}
+			var all []scored
+			categories := make(map[Category]int)
+			hand := make([]cards.Card, 5)
+			for a := 0; a < len(d); a++ {
+				for b := a + 1; b < len(d); b++ {
+					for c := b + 1; c < len(d); c++ {
+						for e := c + 1; e < len(d); e++ {
+							for f := e + 1; f < len(d); f++ {
+								hand[0], hand[1], hand[2], hand[3], hand[4] = d[a], d[b], d[c], d[e], d[f]
+								v := rs.eval.Evaluate(hand)
+								key, category := referenceRank(hand, rs.rules)
+								if v.Category() != category {
+									t.Fatalf("%v: category %s, want %s", hand, v.Category(), category)
+								}
+								categories[category]++
+								all = append(all, scored{v, key})
+							}
+						}
+					}
+				}
+			}
+
+			sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+			for i := 1; i < len(all); i++ {
+				prev, cur := all[i-1], all[i]
+				if (prev.value == cur.value) != (prev.key == cur.key) || prev.key > cur.key {
+					t.Fatalf("order mismatch: values %x, %x; reference %x, %x", prev.value, cur.value, prev.key, cur.key)
+				}
+			}
+
+			if rs.name == "standard" {
+				want := map[Category]int{
+					StraightFlush: 40, FourOfAKind: 624, FullHouse: 3744, Flush: 5108, Straight: 10200,
+					ThreeOfAKind: 54912, TwoPair: 123552, OnePair: 1098240, HighCard: 1302540,
+				}
+				for c, n := range want {
+					if categories[c] != n {
+						t.Errorf("%s: %d hands, want %d", c, categories[c], n)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Семикарточные руки: лучшая пятёрка оценщика против перебора всех 21 сочетаний
+func TestEvaluateSevenCardsAgainstReference(t *testing.T) {
+	samples := 50000
+	if testing.Short() {
+		samples = 5000
+	}
+	for _, rs := range ruleSets {
+		t.Run(rs.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			d := deck(rs.rules.MinRank)
+			prevValue, prevKey := HandValue(0), 0
+			for i := 0; i < samples; i++ {
+				rng.Shuffle(len(d), func(a, b int) { d[a], d[b] = d[b], d[a] })
+				hand := d[:7]
+				v := rs.eval.Evaluate(hand)
+				key, category := referenceBest(hand, rs.rules)
+				if v.Category() != category {
+					t.Fatalf("%v: category %s, want %s", hand, v.Category(), category)
+				}
+				if Compare(v, prevValue) != compareInts(key, prevKey) {
+					t.Fatalf("%v: compares %d to previous hand, reference says %d", hand, Compare(v, prevValue), compareInts(key, prevKey))
+				}
+				prevValue, prevKey = v, key
+			}
+		})
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	}
+	return 0
+}
+
+func TestEvaluateKickersAndGroups(t *testing.T) {
+	tests := []struct {
+		name          string
+		eval          *Evaluator
+		better, worse string
+	}{
+		{"pair kicker", standard, "As Ad Kc 9h 4s 3d 2c", "Ah Ac Qd 9s 4h 3c 2d"},
+		{"fifth kicker decides", standard, "Kh Kd Qs Js 9c 3h 2d", "Ks Kc Qd Jh 8c 3s 2h"},
+		{"third pair gives the kicker", standard, "Qs Qd 8h 8c 5s 5d 4h", "Qh Qc 8s 8d 4s 4d 3h"},
+		{"two trips make the best full house", standard, "9s 9d 9h 7c 7s 7d 2h", "9c 9h 9d 6c 6s 6d Ah"},
+		{"quads take the best kicker", standard, "5s 5d 5h 5c Ks Kd Kh", "5s 5d 5h 5c Qs Qd Qh"},
+		{"six-high straight beats the wheel", standard, "6s 5d 4h 3c 2s Kd Qh", "As 5d 4h 3c 2s Kd Qh"},
+		{"flush over straight", standard, "As 9s 7s 4s 2s Kd Qh", "Ts 9d 8h 7c 6s Kd Qh"},
+		{"short deck: flush beats full house", shortDeck, "As Ts 8s 7s 6s Kd Qh", "Ks Kd Kh Qc Qs 7d 6h"},
+		{"short deck: A-6-7-8-9 is a straight", shortDeck, "As 6d 7h 8c 9s Kd Qh", "Ah Ad Kc Qs Jh 9d 7c"},
+		{"deuce-to-seven: ace is always high", deuceToSeven, "As 5d 4h 3c 2s", "Ks Qd Jh 9c 8s"},
+		{"deuce-to-seven: straights count", deuceToSeven, "6s 5d 4h 3c 2s", "As Kd Qh Jc 9s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			better := tt.eval.Evaluate(parseHand(t, tt.better))
+			worse := tt.eval.Evaluate(parseHand(t, tt.worse))
+			if Compare(better, worse) != 1 {
+				t.Fatalf("%s (%s) should beat %s (%s)", tt.better, better, tt.worse, worse)
+			}
+		})
+	}
+
+	// Шестая и седьмая карты не играют: у обеих рук A-K-Q-J-9
+	if v := Compare(Evaluate(parseHand(t, "Ah Kd Qs Js 9c 3h 2d")), Evaluate(parseHand(t, "As Kc Qd Jh 9d 4s 3c"))); v != 0 {
+		t.Fatalf("hands differing only in unused cards compare as %d, want 0", v)
+	}
+
+	// Лучшая рука лоубола 2-7 — 7-5-4-3-2 разномастные
+	best := EvaluateDeuceToSeven(parseHand(t, "7s 5d 4h 3c 2s"))
+	if got := LowballString(best); got != "7-5-4-3-2" {
+		t.Fatalf("LowballString = %s, want 7-5-4-3-2", got)
+	}
+	if CompareLowball(best, EvaluateDeuceToSeven(parseHand(t, "7s 6d 4h 3c 2s"))) != 1 {
+		t.Fatal("7-5-4-3-2 should beat 7-6-4-3-2 in deuce-to-seven")
+	}
+}
+
+func BenchmarkEvaluate7(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	d := deck(cards.Two)
+	hands := make([][]cards.Card, 1024)
+	for i := range hands {
+		rng.Shuffle(len(d), func(a, b int) { d[a], d[b] = d[b], d[a] })
+		hands[i] = append([]cards.Card(nil), d[:7]...)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Evaluate(hands[i&1023])
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "hands/s")
+}
@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"math/bits"
+	"poker/internal/modules/room/cards"
+	"strings"
+)
+
+// LowValue — сила лоу-руки «восемь или ниже»: пять разных рангов от туза до восьмёрки,
+// старшая карта — в старшем полубайте. Чем меньше, тем лучше; 0 — лоу нет
+type LowValue uint32
+
+// lowFive — лоу по 8-битной маске рангов A..8, считается один раз при старте
+var lowFive [1 << 8]LowValue
+
+func init() {
+	for mask := range lowFive {
+		if bits.OnesCount8(uint8(mask)) < 5 {
+			continue
+		}
+		// Пять младших рангов маски, упакованные от старшего к младшему
+		var picked [5]uint32
+		n := 0
+		for b := 0; b < 8 && n < 5; b++ {
+			if mask&(1<<b) != 0 {
+				picked[n] = uint32(b + 1) // туз — 1, восьмёрка — 8
+				n++
+			}
+		}
+		var v uint32
+		for i := 4; i >= 0; i-- {
+			v = v<<4 | picked[i]
+		}
+		lowFive[mask] = LowValue(v)
+	}
+}
+
+// lowBit — бит ранга в лоу-маске; для карт старше восьмёрки — 0
+func lowBit(c cards.Card) uint8 {
+	switch r := c.Rank(); {
+	case r == cards.Ace:
+		return 1
+	case r <= cards.Eight:
+		return 1 << (uint8(r) + 1)
+	}
+	return 0
+}
+
+// Qualified — есть лоу «восемь или ниже»
+func (v LowValue) Qualified() bool { return v != 0 }
+
+// Better — v лучше other
+func (v LowValue) Better(other LowValue) bool {
+	return v != 0 && (other == 0 || v < other)
+}
+
+func (v LowValue) String() string {
+	if v == 0 {
+		return "No Low"
+	}
+	names := []string{"", "A", "2", "3", "4", "5", "6", "7", "8"}
+	parts := make([]string, 0, 5)
+	for shift := 16; shift >= 0; shift -= 4 {
+		parts = append(parts, names[(v>>uint(shift))&0xf])
+	}
+	return "Low " + strings.Join(parts, "-")
+}
+
+// EvaluateLow — лучшее лоу из любых пяти карт (холдем хай-лоу)
+func EvaluateLow(hand []cards.Card) LowValue {
+	var mask uint8
+	for _, c := range hand {
+		mask |= lowBit(c)
+	}
+	return lowFive[mask]
+}
+
+// EvaluateOmahaLow — лучшее лоу ровно из двух карт руки и трёх карт борда
+func EvaluateOmahaLow(hole, board []cards.Card) LowValue {
+	var best LowValue
+	for a := 0; a < len(hole); a++ {
+		for b := a + 1; b < len(hole); b++ {
+			h := lowBit(hole[a]) | lowBit(hole[b])
+			if bits.OnesCount8(h) != 2 {
+				continue
+			}
+			for c := 0; c < len(board); c++ {
+				for d := c + 1; d < len(board); d++ {
+					for e := d + 1; e < len(board); e++ {
+						m := h | lowBit(board[c]) | lowBit(board[d]) | lowBit(board[e])
+						if v := lowFive[m]; bits.OnesCount8(m) == 5 && v.Better(best) {
+							best = v
+						}
+					}
+				}
+			}
+		}
+	}
+	return best
+}
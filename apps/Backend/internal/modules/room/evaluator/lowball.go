@@ -0,0 +1,30 @@
+package evaluator
+
+import (
+	"poker/internal/modules/room/cards"
+	"strings"
+)
+
+// EvaluateDeuceToSeven — пятикарточная рука для лоубола 2-7: туз всегда старший,
+// стриты и флеши идут в минус. Чем меньше значение, тем лучше рука; лучшая — 7-5-4-3-2 разномастные
+func EvaluateDeuceToSeven(hand []cards.Card) HandValue {
+	return deuceToSeven.Evaluate(hand)
+}
+
+// CompareLowball — как Compare, но для лоубола: выигрывает меньшее значение
+func CompareLowball(a, b HandValue) int {
+	return Compare(b, a)
+}
+
+// LowballString — описание руки в лоуболе: "7-5-4-3-2" для старшей карты, иначе название комбинации
+func LowballString(v HandValue) string {
+	if v.Category() != HighCard {
+		return v.Category().String()
+	}
+	names := []string{"2", "3", "4", "5", "6", "7", "8", "9", "T", "J", "Q", "K", "A"}
+	parts := make([]string, 0, 5)
+	for shift := 16; shift >= 0; shift -= 4 {
+		parts = append(parts, names[(v>>uint(shift))&0xf])
+	}
+	return strings.Join(parts, "-")
+}
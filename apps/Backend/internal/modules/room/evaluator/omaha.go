@@ -0,0 +1,32 @@
+package evaluator
+
+import "poker/internal/modules/room/cards"
+
+// EvaluateOmaha — лучшая комбинация ровно из двух карт руки и ровно трёх карт борда
+func EvaluateOmaha(hole, board []cards.Card) HandValue {
+	return standard.EvaluateOmaha(hole, board)
+}
+
+// EvaluateOmaha — то же по правилам оценщика
+func (e *Evaluator) EvaluateOmaha(hole, board []cards.Card) HandValue {
+	var (
+		best HandValue
+		five [5]cards.Card
+	)
+	for a := 0; a < len(hole); a++ {
+		for b := a + 1; b < len(hole); b++ {
+			five[0], five[1] = hole[a], hole[b]
+			for i := 0; i < len(board); i++ {
+				for j := i + 1; j < len(board); j++ {
+					for k := j + 1; k < len(board); k++ {
+						five[2], five[3], five[4] = board[i], board[j], board[k]
+						if v := e.Evaluate(five[:]); v > best {
+							best = v
+						}
+					}
+				}
+			}
+		}
+	}
+	return best
+}
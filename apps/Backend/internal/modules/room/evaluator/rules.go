@@ -0,0 +1,47 @@
+package evaluator
+
+import "poker/internal/modules/room/cards"
+
+// Rules — правила старшинства комбинаций для разновидности игры
+type Rules struct {
+	MinRank             cards.Rank // младший ранг колоды: двойка, в шорт-деке — шестёрка
+	FlushBeatsFullHouse bool       // шорт-дек: флеш старше фулл-хауса
+	NoWheel             bool       // туз всегда старший: A-2-3-4-5 — не стрит (лоубол 2-7)
+}
+
+var (
+	StandardRules     = Rules{MinRank: cards.Two}
+	ShortDeckRules    = Rules{MinRank: cards.Six, FlushBeatsFullHouse: true}
+	DeuceToSevenRules = Rules{MinRank: cards.Two, NoWheel: true}
+)
+
+// Evaluator оценивает руки по заданным правилам
+type Evaluator struct {
+	order        [StraightFlush + 1]uint8
+	straightHigh [1 << cards.NumRanks]uint8
+}
+
+var (
+	standard     = New(StandardRules)
+	shortDeck    = New(ShortDeckRules)
+	deuceToSeven = New(DeuceToSevenRules)
+)
+
+// New строит оценщик с таблицами под правила
+func New(rules Rules) *Evaluator {
+	e := &Evaluator{}
+	for c := HighCard; c <= StraightFlush; c++ {
+		e.order[c] = uint8(c)
+	}
+	if rules.FlushBeatsFullHouse {
+		e.order[Flush], e.order[FullHouse] = e.order[FullHouse], e.order[Flush]
+	}
+	buildStraights(&e.straightHigh, rules.MinRank, !rules.NoWheel)
+	return e
+}
+
+// ShortDeck — оценщик для шорт-дека (6+)
+func ShortDeck() *Evaluator { return shortDeck }
+
+// Standard — оценщик для стандартной колоды
+func Standard() *Evaluator { return standard }
@@ -0,0 +1,33 @@
+package evaluator
+
+import "poker/internal/modules/room/cards"
+
+// topFive — пять старших рангов 13-битной маски, упакованные по полубайту. Считается один раз при старте
+var topFive [1 << cards.NumRanks]uint32
+
+func init() {
+	for mask := range topFive {
+		topFive[mask] = kickers(uint16(mask), 5)
+	}
+}
+
+// buildStraights заполняет таблицу стритов: старшая карта стрита + 1 (0 — стрита нет).
+// Младший стрит — туз и четыре младших ранга колоды, туз в нём считается младшей картой
+func buildStraights(table *[1 << cards.NumRanks]uint8, minRank cards.Rank, wheelAllowed bool) {
+	wheelHigh := int(minRank) + 3
+	wheel := uint16(1)<<cards.Ace | uint16(0xf)<<minRank
+
+	for mask := range table {
+		m := uint16(mask)
+		for high := cards.NumRanks - 1; high >= wheelHigh+1; high-- {
+			run := uint16(0x1f) << (high - 4)
+			if m&run == run {
+				table[mask] = uint8(high + 1)
+				break
+			}
+		}
+		if wheelAllowed && table[mask] == 0 && m&wheel == wheel {
+			table[mask] = uint8(wheelHigh + 1)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package fairness
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"poker/internal/modules/room/cards"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditAlpha — уровень значимости тестов аудита
+const AuditAlpha = 0.01
+
+// maxFailedPositions — сколько позиций может не пройти хи-квадрат случайно: при 52 позициях
+// и AuditAlpha в среднем проваливается половина позиции, больше четырёх — почти невероятно
+const maxFailedPositions = 4
+
+// AuditConfig — сколько колод сдать и как. Deal вызывается из нескольких горутин сразу
+type AuditConfig struct {
+	Decks   int
+	Workers int
+	MinRank cards.Rank
+	Source  string // описание источника для отчёта
+	Deal    func() cards.Deck
+}
+
+// AuditReport — итог статистической проверки тасования
+type AuditReport struct {
+	Algorithm string
+	Source    string
+	Decks     int
+	DeckSize  int
+	Started   time.Time
+	Duration  time.Duration
+
+	// Хи-квадрат по таблице «позиция × карта»: каждая карта должна одинаково часто
+	// оказываться на каждой позиции
+	ChiSquare       float64
+	DF              int
+	PValue          float64
+	PositionPValues []float64
+	FailedPositions int
+
+	// Корреляция соседних карт колоды. У случайной перестановки она равна -1/(n-1), а не нулю:
+	// карта не может повториться
+	SerialCorrelation    float64
+	ExpectedCorrelation  float64
+	CorrelationTolerance float64
+}
+
+// Passed — все тесты пройдены
+func (r AuditReport) Passed() bool {
+	return r.PValue >= AuditAlpha &&
+		r.FailedPositions <= maxFailedPositions &&
+		math.Abs(r.SerialCorrelation-r.ExpectedCorrelation) <= r.CorrelationTolerance
+}
+
+// auditCounts — частоты одной горутины
+type auditCounts struct {
+	positions              [][]int64 // [позиция][карта]
+	sumX, sumY, sumXX      float64
+	sumYY, sumXY, pairs    float64
+	cardIndex              [cards.NumRanks * cards.NumSuits]int
+	deckSize, decksCounted int
+}
+
+func newAuditCounts(minRank cards.Rank) *auditCounts {
+	c := &auditCounts{}
+	for i, card := range cards.NewDeckFrom(minRank) {
+		c.cardIndex[card] = i
+		c.deckSize++
+	}
+	c.positions = make([][]int64, c.deckSize)
+	for i := range c.positions {
+		c.positions[i] = make([]int64, c.deckSize)
+	}
+	return c
+}
+
+func (c *auditCounts) add(deck cards.Deck) {
+	prev := -1
+	for pos, card := range deck {
+		idx := c.cardIndex[card]
+		c.positions[pos][idx]++
+		if prev >= 0 {
+			x, y := float64(prev), float64(idx)
+			c.sumX += x
+			c.sumY += y
+			c.sumXX += x * x
+			c.sumYY += y * y
+			c.sumXY += x * y
+			c.pairs++
+		}
+		prev = idx
+	}
+	c.decksCounted++
+}
+
+func (c *auditCounts) merge(o *auditCounts) {
+	for pos := range c.positions {
+		for idx := range c.positions[pos] {
+			c.positions[pos][idx] += o.positions[pos][idx]
+		}
+	}
+	c.sumX += o.sumX
+	c.sumY += o.sumY
+	c.sumXX += o.sumXX
+	c.sumYY += o.sumYY
+	c.sumXY += o.sumXY
+	c.pairs += o.pairs
+	c.decksCounted += o.decksCounted
+}
+
+// Audit сдаёт cfg.Decks колод через cfg.Deal и проверяет равномерность и независимость позиций
+func Audit(cfg AuditConfig) AuditReport {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	report := AuditReport{Algorithm: Algorithm, Source: cfg.Source, Started: time.Now()}
+	total := newAuditCounts(cfg.MinRank)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for w := 0; w < workers; w++ {
+		n := cfg.Decks / workers
+		if w < cfg.Decks%workers {
+			n++
+		}
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			local := newAuditCounts(cfg.MinRank)
+			for i := 0; i < n; i++ {
+				local.add(cfg.Deal())
+			}
+			mu.Lock()
+			total.merge(local)
+			mu.Unlock()
+		}(n)
+	}
+	wg.Wait()
+
+	report.Duration = time.Since(report.Started)
+	report.Decks = total.decksCounted
+	report.DeckSize = total.deckSize
+	report.chiSquare(total)
+	report.serialCorrelation(total)
+	return report
+}
+
+func (r *AuditReport) chiSquare(c *auditCounts) {
+	n := c.deckSize
+	expected := float64(c.decksCounted) / float64(n)
+	r.PositionPValues = make([]float64, n)
+	for pos := 0; pos < n; pos++ {
+		var chi float64
+		for _, observed := range c.positions[pos] {
+			d := float64(observed) - expected
+			chi += d * d / expected
+		}
+		r.ChiSquare += chi
+		r.PositionPValues[pos] = chiSquarePValue(chi, n-1)
+		if r.PositionPValues[pos] < AuditAlpha {
+			r.FailedPositions++
+		}
+	}
+	// Суммы по строкам и столбцам фиксированы — у таблицы (n-1)² степеней свободы
+	r.DF = (n - 1) * (n - 1)
+	r.PValue = chiSquarePValue(r.ChiSquare, r.DF)
+}
+
+func (r *AuditReport) serialCorrelation(c *auditCounts) {
+	p := c.pairs
+	cov := c.sumXY/p - (c.sumX/p)*(c.sumY/p)
+	varX := c.sumXX/p - (c.sumX/p)*(c.sumX/p)
+	varY := c.sumYY/p - (c.sumY/p)*(c.sumY/p)
+	r.SerialCorrelation = cov / math.Sqrt(varX*varY)
+	r.ExpectedCorrelation = -1 / float64(c.deckSize-1)
+	// Четыре стандартные ошибки коэффициента корреляции
+	r.CorrelationTolerance = 4 / math.Sqrt(p)
+}
+
+// chiSquarePValue — вероятность получить статистику не меньше x при df степенях свободы
+// (приближение Уилсона — Хилферти, точное для df от нескольких десятков)
+func chiSquarePValue(x float64, df int) float64 {
+	k := float64(df)
+	z := (math.Cbrt(x/k) - (1 - 2/(9*k))) / math.Sqrt(2/(9*k))
+	return 0.5 * math.Erfc(z/math.Sqrt2)
+}
+
+// WriteTo пишет отчёт в markdown, чтобы его можно было приложить к проверке честности
+func (r AuditReport) WriteTo(w io.Writer) (int64, error) {
+	verdict := "PASSED"
+	if !r.Passed() {
+		verdict = "FAILED"
+	}
+	minP, maxP := 1.0, 0.0
+	for _, p := range r.PositionPValues {
+		minP = math.Min(minP, p)
+		maxP = math.Max(maxP, p)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# RNG audit: %s\n\n", verdict)
+	fmt.Fprintf(&b, "- Date: %s\n", r.Started.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Shuffle algorithm: %s\n", r.Algorithm)
+	fmt.Fprintf(&b, "- Seed source: %s\n", r.Source)
+	fmt.Fprintf(&b, "- Decks dealt: %d (%d cards each)\n", r.Decks, r.DeckSize)
+	fmt.Fprintf(&b, "- Duration: %s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "- Significance level: %.2f\n\n", AuditAlpha)
+
+	fmt.Fprintf(&b, "## Chi-square: card frequency by position\n\n")
+	fmt.Fprintf(&b, "- Whole table: χ² = %.2f, df = %d, p = %.4f\n", r.ChiSquare, r.DF, r.PValue)
+	fmt.Fprintf(&b, "- Positions below α: %d of %d (at most %d allowed), p-values %.4f..%.4f\n\n",
+		r.FailedPositions, len(r.PositionPValues), maxFailedPositions, minP, maxP)
+	fmt.Fprintf(&b, "| Position | p-value |\n|---:|---:|\n")
+	for pos, p := range r.PositionPValues {
+		fmt.Fprintf(&b, "| %d | %.4f |\n", pos+1, p)
+	}
+
+	fmt.Fprintf(&b, "\n## Serial correlation of adjacent cards\n\n")
+	fmt.Fprintf(&b, "- Observed: %.6f, expected: %.6f, tolerance: ±%.6f\n",
+		r.SerialCorrelation, r.ExpectedCorrelation, r.CorrelationTolerance)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
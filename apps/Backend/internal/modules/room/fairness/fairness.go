@@ -0,0 +1,98 @@
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/rng"
+	"sort"
+	"strings"
+)
+
+// Algorithm — как из сидов получается порядок колоды, чтобы игрок мог повторить его сам:
+//
+//  1. seed = HMAC-SHA256(ключ — серверный сид, сообщение — клиентские сиды "userID=seed",
+//     отсортированные по userID и разделённые "\n")
+//  2. поток случайных байт: SHA256(seed || счётчик), счётчик — uint64 big-endian с нуля
+//  3. из потока читаются uint32 big-endian; число для диапазона [0, n) берётся по модулю,
+//     значения из неполного последнего интервала отбрасываются
+//  4. упорядоченная колода (масти ♠ ♥ ♦ ♣, в каждой — от младшей карты к тузу) тасуется
+//     Фишером — Йейтсом: для i от последней карты к первой меняются местами i и [0, i]
+const Algorithm = "hmac-sha256-fisher-yates-v1"
+
+// MaxClientSeedLength — длина клиентского сида в символах
+const MaxClientSeedLength = 64
+
+// NewServerSeed — новый секретный серверный сид (32 случайных байта из rng.Default в hex)
+func NewServerSeed() string {
+	b := make([]byte, 32)
+	rng.Read(rng.Default(), b)
+	return hex.EncodeToString(b)
+}
+
+// Commit — публикуемый до раздачи хэш серверного сида
+func Commit(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckClientSeed проверяет сид, который игрок добавляет к раздаче
+func CheckClientSeed(seed string) error {
+	if strings.TrimSpace(seed) == "" {
+		return fmt.Errorf("client seed is empty")
+	}
+	if len(seed) > MaxClientSeedLength {
+		return fmt.Errorf("client seed is longer than %d characters", MaxClientSeedLength)
+	}
+	if strings.ContainsAny(seed, "=\n") {
+		return fmt.Errorf("client seed must not contain '=' or line breaks")
+	}
+	return nil
+}
+
+// Seed смешивает серверный сид с клиентскими: ни сервер, ни игроки по отдельности не знают итог
+func Seed(serverSeed string, clientSeeds map[string]string) []byte {
+	ids := make([]string, 0, len(clientSeeds))
+	for id := range clientSeeds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		lines = append(lines, id+"="+clientSeeds[id])
+	}
+
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	return mac.Sum(nil)
+}
+
+// Derive — независимый сид для повторного тасования в той же раздаче (например, сброса в дро)
+func Derive(seed []byte, label string) []byte {
+	mac := hmac.New(sha256.New, seed)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// Shuffle тасует колоду по сиду; один и тот же сид всегда даёт один и тот же порядок
+func Shuffle(d cards.Deck, seed []byte) {
+	d.Shuffle(rng.NewSeeded(seed))
+}
+
+// Deal — колода раздачи: упорядоченная колода от minRank, перетасованная по сидам
+func Deal(serverSeed string, clientSeeds map[string]string, minRank cards.Rank) cards.Deck {
+	deck := cards.NewDeckFrom(minRank)
+	Shuffle(deck, Seed(serverSeed, clientSeeds))
+	return deck
+}
+
+// Verify проверяет, что раскрытый сид совпадает с опубликованным хэшем, и восстанавливает колоду
+func Verify(serverSeed, commitment string, clientSeeds map[string]string, minRank cards.Rank) (cards.Deck, error) {
+	if !strings.EqualFold(Commit(serverSeed), strings.TrimSpace(commitment)) {
+		return nil, fmt.Errorf("server seed does not match the commitment")
+	}
+	return Deal(serverSeed, clientSeeds, minRank), nil
+}
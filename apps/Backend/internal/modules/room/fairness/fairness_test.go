@@ -0,0 +1,90 @@
+package fairness
+
+import (
+	"poker/internal/modules/room/cards"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testServerSeed = "6f1c2a9e4b7d3f0812ab45cd67ef8901a2b3c4d5e6f708192a3b4c5d6e7f8091"
+
+func TestDealIsDeterministic(t *testing.T) {
+	seeds := map[string]string{"alice": "lucky", "bob": "42"}
+	deck := Deal(testServerSeed, seeds, cards.Two)
+
+	// Те же сиды в другом порядке вставки дают ту же колоду
+	same := map[string]string{}
+	same["bob"] = "42"
+	same["alice"] = "lucky"
+	if again := Deal(testServerSeed, same, cards.Two); !reflect.DeepEqual(again, deck) {
+		t.Fatalf("same seeds dealt different decks:\n%v\n%v", deck, again)
+	}
+
+	// Колода — перестановка полной колоды, а не набор повторов
+	sorted := append(cards.Deck(nil), deck...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	full := cards.NewDeckFrom(cards.Two)
+	sort.Slice(full, func(i, j int) bool { return full[i] < full[j] })
+	if !reflect.DeepEqual(sorted, full) {
+		t.Fatalf("deal is not a permutation of the deck: %v", deck)
+	}
+	if reflect.DeepEqual(deck, cards.NewDeckFrom(cards.Two)) {
+		t.Fatal("deck was not shuffled")
+	}
+
+	// Любой изменённый сид меняет колоду
+	changed := []struct {
+		name   string
+		server string
+		seeds  map[string]string
+	}{
+		{"server seed", testServerSeed[1:] + "0", seeds},
+		{"client seed", testServerSeed, map[string]string{"alice": "lucky", "bob": "43"}},
+		{"extra player", testServerSeed, map[string]string{"alice": "lucky", "bob": "42", "carol": "x"}},
+	}
+	for _, c := range changed {
+		if reflect.DeepEqual(Deal(c.server, c.seeds, cards.Two), deck) {
+			t.Errorf("changed %s dealt the same deck", c.name)
+		}
+	}
+
+	// Повторное тасование в раздаче: тот же номер — тот же порядок, другой номер — другой
+	seed := Seed(testServerSeed, seeds)
+	first, again, second := cards.NewDeckFrom(cards.Six), cards.NewDeckFrom(cards.Six), cards.NewDeckFrom(cards.Six)
+	Shuffle(first, Derive(seed, "reshuffle-1"))
+	Shuffle(again, Derive(seed, "reshuffle-1"))
+	Shuffle(second, Derive(seed, "reshuffle-2"))
+	if !reflect.DeepEqual(first, again) {
+		t.Fatal("same reshuffle nonce gave different orders")
+	}
+	if reflect.DeepEqual(first, second) {
+		t.Fatal("different reshuffle nonces gave the same order")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	seeds := map[string]string{"alice": "lucky"}
+	commitment := Commit(testServerSeed)
+
+	deck, err := Verify(testServerSeed, commitment, seeds, cards.Six)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Deal(testServerSeed, seeds, cards.Six); !reflect.DeepEqual(deck, want) || len(deck) != 36 {
+		t.Fatalf("verified deck = %v, want %v", deck, want)
+	}
+
+	// Хэш, скопированный в другом регистре и с пробелами, тоже подходит
+	if _, err := Verify(testServerSeed, " "+strings.ToUpper(commitment)+"\n", seeds, cards.Six); err != nil {
+		t.Fatalf("upper-case commitment rejected: %v", err)
+	}
+
+	if _, err := Verify(testServerSeed+"0", commitment, seeds, cards.Six); err == nil {
+		t.Fatal("seed that does not match the commitment accepted")
+	}
+	if _, err := Verify(testServerSeed, Commit("another seed"), seeds, cards.Six); err == nil {
+		t.Fatal("commitment of another seed accepted")
+	}
+}
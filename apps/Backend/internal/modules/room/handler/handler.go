@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"log"
@@ -13,9 +14,11 @@ import (
 	"go.uber.org/zap"
 	authService "poker/internal/modules/auth/service"
 	"poker/internal/modules/room/dto"
+	"poker/internal/modules/room/fairness"
 	"poker/internal/modules/room/manager"
 	service "poker/internal/modules/room/service"
 	room_temporal "poker/internal/modules/room/temporal"
+	"poker/internal/modules/room/variant"
 )
 
 type RoomHandler struct {
@@ -64,6 +67,61 @@ func (h *RoomHandler) CreateRoom(c *fiber.Ctx) error {
 		})
 	}
 
+	blinds, err := room_temporal.ParseLimits(req.Limits)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if _, err := room_temporal.ParseBuyIn(req.MinBuyIn, req.MaxBuyIn, blinds); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if _, err := room_temporal.ParseRake(req.Type, req.RakePercent, req.RakeCap); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	game, err := variant.ParseGameType(req.GameType)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if max := variant.Get(game).MaxPlayers; max > 0 && req.MaxPlayers > max {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("%s allows at most %d players", game, max),
+		})
+	}
+
+	rotation, err := room_temporal.ParseRotation(req.Rotation, req.RotationHands, req.DealersChoice)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if max := rotation.MaxPlayers(); max > 0 && req.MaxPlayers > max {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("this rotation allows at most %d players", max),
+		})
+	}
+
+	if _, err := variant.ParseBettingStructure(req.BettingStructure); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if _, err := room_temporal.ParseClock(req.ActionTimeout, req.TimeBank); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	roomID, err := h.service.CreateRoom(ctx, req)
 	if err != nil {
 		h.logger.Error("Failed to create room", zap.Error(err))
@@ -145,6 +203,35 @@ func (h *RoomHandler) JoinRoom(c *websocket.Conn, roomID, userID string) error {
 			})
 			continue
 		}
+		// Бай-ин: фишки из кошелька переносятся на стол
+		if req.Activity == "buy-in" && len(req.Args) > 0 {
+			amount, err := strconv.ParseInt(req.Args[0], 10, 64)
+			if err != nil {
+				_ = c.WriteMessage(websocket.TextMessage, []byte("❌ Invalid buy-in amount"))
+				continue
+			}
+			_ = h.temporal.SignalWorkflow(context.Background(), "room_"+req.RoomID, "", "buy-in", room_temporal.BuyInSignal{
+				UserID: req.UserID,
+				Amount: amount,
+			})
+			continue
+		}
+		// Сид игрока для честного тасования следующих раздач
+		if req.Activity == "client-seed" && len(req.Args) > 0 {
+			_ = h.temporal.SignalWorkflow(context.Background(), "room_"+req.RoomID, "", "client-seed", room_temporal.ClientSeedSignal{
+				UserID: req.UserID,
+				Seed:   req.Args[0],
+			})
+			continue
+		}
+		// Дилерс-чойс: баттон выбирает следующую игру
+		if req.Activity == "choose-game" && len(req.Args) > 0 {
+			_ = h.temporal.SignalWorkflow(context.Background(), "room_"+req.RoomID, "", "choose-game", room_temporal.ChooseGameSignal{
+				UserID: req.UserID,
+				Game:   req.Args[0],
+			})
+			continue
+		}
 
 		h.logger.Info("📩 Player action",
 			zap.String("roomID", req.RoomID),
@@ -233,13 +320,13 @@ func (h *RoomHandler) PlayerAction(c *fiber.Ctx) error {
 
 // AvailableActions godoc
 // @Summary Получение доступных действий игрока
-// @Description Возвращает список допустимых ходов на текущий момент
+// @Description Возвращает список допустимых ходов на текущий момент и допустимые суммы для каждого
 // @Tags Room
 // @Accept json
 // @Produce json
 // @Param roomID query string true "ID комнаты"
 // @Param userID query string true "ID игрока"
-// @Success 200 {object} map[string][]string
+// @Success 200 {object} map[string]interface{}
 // @Router /room/available-actions [get]
 // @Security BearerAuth
 func (h *RoomHandler) AvailableActions(c *fiber.Ctx) error {
@@ -268,8 +355,25 @@ func (h *RoomHandler) AvailableActions(c *fiber.Ctx) error {
 		})
 	}
 
+	optionsResp, err := h.temporal.QueryWorkflow(context.Background(), "room_"+roomID, "", "action-options", userID)
+	if err != nil {
+		h.logger.Error("❌ Failed to query action-options", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{
+			"error": "failed to query workflow",
+		})
+	}
+
+	var options []room_temporal.ActionOption
+	if err := optionsResp.Get(&options); err != nil {
+		h.logger.Error("❌ Failed to decode query result", zap.Error(err))
+		return c.Status(500).JSON(fiber.Map{
+			"error": "invalid query result",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"actions": available,
+		"options": options,
 	})
 }
 
@@ -298,6 +402,43 @@ func (h *RoomHandler) DealCards(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"message": "cards dealt"})
 }
 
+// VerifyShuffle godoc
+// @Summary Проверка тасования
+// @Description Сверяет раскрытый серверный сид с хэшем, опубликованным до раздачи, и восстанавливает порядок колоды
+// @Tags Room
+// @Accept json
+// @Produce json
+// @Param body body dto.VerifyShuffleRequest true "Сиды раздачи"
+// @Success 200 {object} dto.VerifyShuffleResponse
+// @Failure 400 {object} map[string]string
+// @Router /room/verify-shuffle [post]
+// @Security BearerAuth
+func (h *RoomHandler) VerifyShuffle(c *fiber.Ctx) error {
+	var req dto.VerifyShuffleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if req.ServerSeed == "" || req.Commitment == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "missing server_seed or commitment"})
+	}
+
+	game, err := variant.ParseGameType(req.GameType)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	resp := dto.VerifyShuffleResponse{Algorithm: fairness.Algorithm}
+	deck, err := fairness.Verify(req.ServerSeed, req.Commitment, req.ClientSeeds, variant.Get(game).MinRank)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	resp.Valid = true
+	for _, card := range deck {
+		resp.Deck = append(resp.Deck, card.String())
+	}
+	return c.JSON(resp)
+}
+
 // AvailableRoomList godoc
 // @Summary Получить список доступных комнат
 // @Description Возвращает список комнат со статусом "waiting"
@@ -352,10 +493,7 @@ func (h *RoomHandler) PlayersById(c *fiber.Ctx) error {
 			continue
 		}
 
-		var balance int64
-		if acc.AccountBalance.CurrentBalance != "" {
-			balance, _ = strconv.ParseInt(acc.AccountBalance.CurrentBalance, 10, 64)
-		}
+		balance := acc.AccountBalance.CurrentBalance
 
 		players = append(players, dto.Player{
 			ID:        acc.ID,
@@ -1,11 +1,19 @@
 package repo
 
 import (
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"poker/internal/modules/ledger"
 	"poker/packages/database"
+	"time"
 )
 
+// ErrNoOpenEscrow — у игрока нет открытого эскроу за этим столом
+var ErrNoOpenEscrow = errors.New("no open escrow")
+
 type RoomRepo struct {
 	db *gorm.DB
 }
@@ -14,6 +22,10 @@ type RoomRepoI interface {
 	CreateRoom(room *database.Room) error
 	UpdateRoomStatus(roomID, status string) error
 	GetWaitingRooms() ([]database.Room, error)
+	GetRoom(roomID string) (database.Room, error)
+	BuyIn(roomID, userID string, amount int64, key string) (string, error)
+	CashOut(roomID, userID string, amount int64, key string) (string, error)
+	SettleHand(roomID, reference string, results, rake map[string]int64, key string) (string, error)
 }
 
 func NewRoomRepo(db *gorm.DB) *RoomRepo {
@@ -47,3 +59,124 @@ func (r *RoomRepo) GetWaitingRooms() ([]database.Room, error) {
 	}
 	return rooms, nil
 }
+
+func (r *RoomRepo) GetRoom(roomID string) (database.Room, error) {
+	var room database.Room
+	err := r.db.Where("room_id = ?", roomID).First(&room).Error
+	return room, err
+}
+
+// BuyIn одной транзакцией переводит amount из кошелька на счёт игрока за столом
+// и записывает его в эскроу. Докупка добавляется к открытому эскроу игрока.
+// Повтор с тем же ключом возвращает ID уже проведённой операции
+func (r *RoomRepo) BuyIn(roomID, userID string, amount int64, key string) (string, error) {
+	var txnID string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		id, done, err := ledger.Lookup(tx, key)
+		if err != nil || done {
+			txnID = id
+			return err
+		}
+
+		posting := ledger.Posting{Reason: ledger.ReasonBuyIn, Reference: roomID, Key: key}
+		txnID, err = ledger.Transfer(tx, posting, ledger.Wallet(userID), ledger.Table(roomID, userID), amount)
+		if err != nil {
+			return err
+		}
+
+		var escrow database.TableEscrow
+		err = tx.Where("room_id = ? AND user_id = ? AND status = ?", roomID, userID, "held").First(&escrow).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&database.TableEscrow{
+				ID:        uuid.New().String(),
+				RoomID:    roomID,
+				UserID:    userID,
+				BuyIn:     amount,
+				Status:    "held",
+				CreatedAt: time.Now().Unix(),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&escrow).Update("buy_in", escrow.BuyIn+amount).Error
+	})
+	return txnID, err
+}
+
+// CashOut закрывает эскроу игрока и возвращает остаток стека в кошелёк
+func (r *RoomRepo) CashOut(roomID, userID string, amount int64, key string) (string, error) {
+	var txnID string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		id, done, err := ledger.Lookup(tx, key)
+		if err != nil || done {
+			txnID = id
+			return err
+		}
+
+		var escrow database.TableEscrow
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("room_id = ? AND user_id = ? AND status = ?", roomID, userID, "held").
+			First(&escrow).Error
+		// Пустой стек после проведённого расчёта: возвращать нечего, повтор ничего не меняет
+		if errors.Is(err, gorm.ErrRecordNotFound) && amount == 0 {
+			return nil
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w for %s", ErrNoOpenEscrow, userID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if amount > 0 {
+			posting := ledger.Posting{Reason: ledger.ReasonCashOut, Reference: roomID, Key: key}
+			txnID, err = ledger.Transfer(tx, posting, ledger.Table(roomID, userID), ledger.Wallet(userID), amount)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&escrow).Updates(map[string]interface{}{
+			"cash_out":   amount,
+			"status":     "settled",
+			"settled_at": time.Now().Unix(),
+		}).Error
+	})
+	return txnID, err
+}
+
+// SettleHand проводит итог раздачи между счетами игроков за столом:
+// results — чистый результат каждого игрока до рейка, выигрыш минус вложенное в банк.
+// Рейк победителей той же транзакцией уходит на счёт заведения
+func (r *RoomRepo) SettleHand(roomID, reference string, results, rake map[string]int64, key string) (string, error) {
+	legs := make([]ledger.Leg, 0, len(results))
+	for userID, amount := range results {
+		legs = append(legs, ledger.Leg{Account: ledger.Table(roomID, userID), Amount: amount})
+	}
+	var (
+		rakeLegs  []ledger.Leg
+		rakeTotal int64
+	)
+	for userID, amount := range rake {
+		rakeLegs = append(rakeLegs, ledger.Leg{Account: ledger.Table(roomID, userID), Amount: -amount})
+		rakeTotal += amount
+	}
+
+	var txnID string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		txnID, err = ledger.Post(tx, ledger.Posting{Reason: ledger.ReasonWin, Reference: reference, Key: key}, legs...)
+		if err != nil || rakeTotal == 0 {
+			return err
+		}
+
+		rakeKey := ""
+		if key != "" {
+			rakeKey = key + "/rake"
+		}
+		rakeLegs = append(rakeLegs, ledger.Leg{Account: ledger.House, Amount: rakeTotal})
+		_, err = ledger.Post(tx, ledger.Posting{Reason: ledger.ReasonRake, Reference: reference, Key: rakeKey}, rakeLegs...)
+		return err
+	})
+	return txnID, err
+}
@@ -0,0 +1,83 @@
+package rng
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Source — источник случайных чисел для тасования и сидов. Реализации не обязаны
+// быть потокобезопасными: каждой горутине — свой источник
+type Source interface {
+	Uint32() uint32
+}
+
+// Default создаёт источник для новых серверных сидов. По умолчанию — crypto/rand;
+// подменяется, например, в аудите или для аппаратного генератора
+var Default = NewCrypto
+
+// cryptoSource читает crypto/rand блоками, чтобы не делать системный вызов на каждое число
+type cryptoSource struct {
+	buf  [512]byte
+	left []byte
+}
+
+// NewCrypto — криптостойкий источник на crypto/rand
+func NewCrypto() Source {
+	return &cryptoSource{}
+}
+
+func (s *cryptoSource) Uint32() uint32 {
+	if len(s.left) < 4 {
+		_, _ = rand.Read(s.buf[:]) // crypto/rand не возвращает ошибок
+		s.left = s.buf[:]
+	}
+	v := binary.BigEndian.Uint32(s.left)
+	s.left = s.left[4:]
+	return v
+}
+
+// seededSource — детерминированный поток: SHA256(seed || счётчик), счётчик — uint64 big-endian с нуля
+type seededSource struct {
+	seed    []byte
+	counter uint64
+	block   []byte
+}
+
+// NewSeeded — воспроизводимый источник: один и тот же сид всегда даёт одну и ту же последовательность
+func NewSeeded(seed []byte) Source {
+	return &seededSource{seed: append([]byte(nil), seed...)}
+}
+
+func (s *seededSource) Uint32() uint32 {
+	if len(s.block) < 4 {
+		var c [8]byte
+		binary.BigEndian.PutUint64(c[:], s.counter)
+		s.counter++
+		sum := sha256.Sum256(append(s.seed[:len(s.seed):len(s.seed)], c[:]...))
+		s.block = sum[:]
+	}
+	v := binary.BigEndian.Uint32(s.block)
+	s.block = s.block[4:]
+	return v
+}
+
+// Intn — равномерное число в [0, n) без перекоса по модулю: значения из неполного
+// последнего интервала отбрасываются
+func Intn(src Source, n int) int {
+	limit := (1 << 32) / uint64(n) * uint64(n)
+	for {
+		if v := uint64(src.Uint32()); v < limit {
+			return int(v % uint64(n))
+		}
+	}
+}
+
+// Read заполняет b случайными байтами из источника
+func Read(src Source, b []byte) {
+	for i := 0; i < len(b); i += 4 {
+		var w [4]byte
+		binary.BigEndian.PutUint32(w[:], src.Uint32())
+		copy(b[i:], w[:])
+	}
+}
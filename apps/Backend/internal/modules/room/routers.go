@@ -32,6 +32,7 @@ func RegisterRoutes(router fiber.Router, db *gorm.DB, logger *zap.Logger, tempor
 	roomGroup.Post("/action", roomHandler.PlayerAction)
 	roomGroup.Get("/available-actions", roomHandler.AvailableActions)
 	roomGroup.Post("/deal-cards", roomHandler.DealCards)
+	roomGroup.Post("/verify-shuffle", roomHandler.VerifyShuffle)
 	roomGroup.Post("/terminate-room", roomHandler.TerminateRoom)
 
 	wsGroup := router.Group("/connection")
@@ -8,6 +8,7 @@ import (
 	"poker/internal/modules/room/dto"
 	"poker/internal/modules/room/repo"
 	room_temporal "poker/internal/modules/room/temporal"
+	"poker/internal/modules/room/variant"
 	"poker/packages/database"
 )
 
@@ -32,6 +33,18 @@ func NewRoomService(repo *repo.RoomRepo, logger *zap.Logger, client client.Clien
 
 func (s *RoomService) CreateRoom(ctx context.Context, req dto.CreateRoomRequest) (string, error) {
 	roomID := uuid.New().String()
+	game, err := variant.ParseGameType(req.GameType)
+	if err != nil {
+		return "", err
+	}
+	betting, err := variant.ParseBettingStructure(req.BettingStructure)
+	if err != nil {
+		return "", err
+	}
+	// Без явной структуры ставок берётся привычная для разновидности: омаха — пот-лимит
+	if req.BettingStructure == "" {
+		betting = variant.Get(game).DefaultBetting
+	}
 	room := &database.Room{
 		Name:       req.Name,
 		RoomID:     roomID,
@@ -39,15 +52,31 @@ func (s *RoomService) CreateRoom(ctx context.Context, req dto.CreateRoomRequest)
 		Limits:     req.Limits,
 		Type:       req.Type,
 		Status:     "waiting",
+
+		MinBuyIn: req.MinBuyIn,
+		MaxBuyIn: req.MaxBuyIn,
+
+		RakePercent: req.RakePercent,
+		RakeCap:     req.RakeCap,
+
+		GameType:         string(game),
+		BettingStructure: string(betting),
+
+		ActionTimeout: req.ActionTimeout,
+		TimeBank:      req.TimeBank,
+
+		Rotation:      req.Rotation,
+		RotationHands: req.RotationHands,
+		DealersChoice: req.DealersChoice,
 	}
 	if err := s.repo.CreateRoom(room); err != nil {
 		return "error creating room: ", err
 	}
 
-	_, err := s.client.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+	_, err = s.client.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
 		ID:        "room_" + roomID,
 		TaskQueue: "room-task-queue",
-	}, room_temporal.StartRoomWorkflow, roomID)
+	}, room_temporal.StartRoomWorkflow, roomID, (*room_temporal.RoomState)(nil))
 
 	return roomID, err
 }
@@ -1,30 +1,10 @@
 package room_temporal
 
+// GetAvailableActions — названия доступных действий; суммы — в GetActionOptions
 func GetAvailableActions(state *RoomState, userID string) []string {
 	var actions []string
-	chips := state.PlayerChips[userID]
-	currentBet := state.CurrentBet
-
-	actions = append(actions, "fold")
-
-	if currentBet == 0 {
-		actions = append(actions, "check")
-		if chips > 0 {
-			actions = append(actions, "bet")
-		}
-	} else {
-		toCall := currentBet - state.PlayerBets[userID]
-		if toCall > 0 && chips >= toCall {
-			actions = append(actions, "call")
-		}
-		if chips > currentBet {
-			actions = append(actions, "raise")
-		}
+	for _, o := range GetActionOptions(state, userID) {
+		actions = append(actions, o.Action)
 	}
-
-	if chips > 0 {
-		actions = append(actions, "allin")
-	}
-
 	return actions
 }
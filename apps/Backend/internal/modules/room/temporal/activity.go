@@ -2,14 +2,19 @@ package room_temporal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 	"go.uber.org/zap"
 	"log"
 	authService "poker/internal/modules/auth/service"
+	historyRepo "poker/internal/modules/history/repo"
+	"poker/internal/modules/ledger"
 	"poker/internal/modules/room/manager"
-	"strconv"
+	"poker/internal/modules/room/repo"
+	"poker/packages/database"
 	"time"
 )
 
@@ -23,9 +28,8 @@ func SendCardToUserActivity(ctx context.Context, roomID, userID, message string)
 	return nil
 }
 
-func SaveGameHistoryActivity(ctx context.Context, state *RoomState) error {
-	log.Printf("💾 Saving history for room %s: %+v", state.RoomID, state.MoveLog)
-	return nil
+func SaveGameHistoryActivity(ctx context.Context, record HandRecord) error {
+	return defaultRoomActivities.SaveGameHistoryActivity(ctx, record)
 }
 
 func sendToAllPlayers(ctx workflow.Context, roomID string, players map[string]bool, message string) {
@@ -85,6 +89,7 @@ type SendWinnerInput struct {
 	WinnerID string
 	Message  string
 	Amount   int64
+	Winners  []WinnerShare // все победители раздачи с их долями
 }
 
 func SendWinnerAnnouncementActivity(ctx context.Context, input SendWinnerInput) error {
@@ -93,79 +98,125 @@ func SendWinnerAnnouncementActivity(ctx context.Context, input SendWinnerInput)
 		"winnerId": input.WinnerID,
 		"message":  input.Message,
 		"amount":   input.Amount,
+		"winners":  input.Winners,
 	}
 	manager.Manager.BroadcastJSON(input.RoomID, payload)
 	return nil
 }
 
-type BalanceUpdateInput struct {
+// EscrowInput — перенос фишек между кошельком игрока и эскроу стола.
+// Key — ключ идемпотентности: ретрай активности не проведёт перенос второй раз
+type EscrowInput struct {
+	RoomID string
 	UserID string
 	Amount int64
+	Key    string
+}
+
+// SettlementInput — итог раздачи для книги фишек: выигрыш до рейка минус вклад в банк
+// и рейк, который заплатил каждый победитель
+type SettlementInput struct {
+	RoomID  string
+	Hand    int
+	Results map[string]int64
+	Rake    map[string]int64
+	Key     string
 }
 
 type RoomActivities struct {
 	AuthService authService.AuthService
+	RoomRepo    *repo.RoomRepo
+	HistoryRepo *historyRepo.HistoryRepo
 }
 
 var defaultRoomActivities *RoomActivities // 👈 глобальная прокси
 
-func GetPlayerBalanceActivity(ctx context.Context, userID string) (int64, error) {
-	return defaultRoomActivities.GetPlayerBalanceActivity(ctx, userID)
+// Активности с фишками возвращают ID операции в книге; повтор с тем же ключом — ID первой
+func BuyInActivity(ctx context.Context, input EscrowInput) (string, error) {
+	return defaultRoomActivities.BuyInActivity(ctx, input)
 }
 
-func DeductChipsFromBalanceActivity(ctx context.Context, input BalanceUpdateInput) error {
-	return defaultRoomActivities.DeductChipsFromBalanceActivity(ctx, input)
+func CashOutActivity(ctx context.Context, input EscrowInput) (string, error) {
+	return defaultRoomActivities.CashOutActivity(ctx, input)
 }
 
-func CreditWinningsActivity(ctx context.Context, input BalanceUpdateInput) error {
-	return defaultRoomActivities.CreditWinningsActivity(ctx, input)
+func SettleHandActivity(ctx context.Context, input SettlementInput) (string, error) {
+	return defaultRoomActivities.SettleHandActivity(ctx, input)
 }
 
-func (a *RoomActivities) GetPlayerBalanceActivity(ctx context.Context, userID string) (int64, error) {
-	account, err := a.AuthService.Me(userID)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get account: %w", err)
-	}
-
-	balance, err := strconv.ParseInt(account.AccountBalance.CurrentBalance, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("invalid balance format: %w", err)
-	}
+func GetRoomActivity(ctx context.Context, roomID string) (database.Room, error) {
+	return defaultRoomActivities.GetRoomActivity(ctx, roomID)
+}
 
-	return balance, nil
+func (a *RoomActivities) GetRoomActivity(ctx context.Context, roomID string) (database.Room, error) {
+	return a.RoomRepo.GetRoom(roomID)
 }
 
-func (a *RoomActivities) DeductChipsFromBalanceActivity(ctx context.Context, input BalanceUpdateInput) error {
-	return a.updateUserBalance(input.UserID, -input.Amount)
+func (a *RoomActivities) BuyInActivity(ctx context.Context, input EscrowInput) (string, error) {
+	if input.Amount <= 0 || input.Key == "" {
+		return "", invalidEscrow(input)
+	}
+	id, err := a.RoomRepo.BuyIn(input.RoomID, input.UserID, input.Amount, input.Key)
+	return id, chipActivityError(err)
 }
 
-func (a *RoomActivities) CreditWinningsActivity(ctx context.Context, input BalanceUpdateInput) error {
-	return a.updateUserBalance(input.UserID, input.Amount)
+func (a *RoomActivities) CashOutActivity(ctx context.Context, input EscrowInput) (string, error) {
+	if input.Amount < 0 || input.Key == "" {
+		return "", invalidEscrow(input)
+	}
+	id, err := a.RoomRepo.CashOut(input.RoomID, input.UserID, input.Amount, input.Key)
+	return id, chipActivityError(err)
+}
+
+// chipActivityError делает неповторяемыми ошибки, которые ретрай не исправит:
+// нехватку фишек и отсутствие эскроу. Сбои базы активность повторит
+func chipActivityError(err error) error {
+	switch {
+	case errors.Is(err, ledger.ErrInsufficientFunds):
+		return temporal.NewNonRetryableApplicationError(err.Error(), "InsufficientFunds", err)
+	case errors.Is(err, repo.ErrNoOpenEscrow):
+		return temporal.NewNonRetryableApplicationError(err.Error(), "NoOpenEscrow", err)
+	}
+	return err
 }
 
-func UpdateUserElo(ctx context.Context, userID string, newElo int64, won bool) error {
-	return defaultRoomActivities.AuthService.UpdateUserElo(userID, newElo, won)
+func invalidEscrow(input EscrowInput) error {
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("invalid escrow operation for %s: amount=%d, key=%q", input.UserID, input.Amount, input.Key),
+		"InvalidEscrow", nil)
 }
 
-func (a *RoomActivities) updateUserBalance(userID string, delta int64) error {
-	acc, err := a.AuthService.Me(userID)
-	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+func (a *RoomActivities) SettleHandActivity(ctx context.Context, input SettlementInput) (string, error) {
+	var sum int64
+	for _, amount := range input.Results {
+		sum += amount
 	}
-
-	balanceInt, err := strconv.ParseInt(acc.AccountBalance.CurrentBalance, 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid balance format: %w", err)
+	if sum != 0 || input.Key == "" {
+		return "", temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("invalid settlement of hand #%d: results sum to %d, key=%q", input.Hand, sum, input.Key),
+			"InvalidSettlement", nil)
 	}
+	id, err := a.RoomRepo.SettleHand(input.RoomID, handSessionID(input.RoomID, input.Hand), input.Results, input.Rake, input.Key)
+	return id, chipActivityError(err)
+}
 
-	newBalance := balanceInt + delta
-	if newBalance < 0 {
-		return fmt.Errorf("insufficient chips: current=%d, delta=%d", balanceInt, delta)
+// SaveGameHistoryActivity сохраняет завершённую раздачу; незаконченную (стол закрыли посреди раздачи) — нет.
+// Отменённая раздача тоже помечается "ended", но улицу конца ставит только finishHand
+func (a *RoomActivities) SaveGameHistoryActivity(ctx context.Context, record HandRecord) error {
+	if record.Hand.Number == 0 || record.Hand.RoundStage != "ended" || record.Hand.EndStreet == "" {
+		return nil
 	}
-
-	if err := a.AuthService.UpdateBalance(userID, newBalance); err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+	session, err := handHistory(record)
+	if err != nil {
+		return err
 	}
-
+	if err := a.HistoryRepo.SaveHand(session); err != nil {
+		return fmt.Errorf("failed to save hand #%d: %w", record.Hand.Number, err)
+	}
+	log.Printf("💾 Saved hand %s: %d actions", session.ID, len(session.Moves))
 	return nil
 }
+
+func UpdateUserElo(ctx context.Context, userID string, newElo int64, won bool) error {
+	return defaultRoomActivities.AuthService.UpdateUserElo(userID, newElo, won)
+}
@@ -0,0 +1,274 @@
+package room_temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"poker/internal/modules/ledger"
+	"poker/internal/modules/room/repo"
+	"poker/packages/database"
+	"reflect"
+	"testing"
+)
+
+// ledgerFixture — книга фишек в отдельной базе sqlite и кошельки игроков с начальным балансом
+func ledgerFixture(t *testing.T, wallets map[string]int64) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(
+		&database.Account{},
+		&database.AccountBalance{},
+		&database.LedgerAccount{},
+		&database.LedgerTransaction{},
+		&database.LedgerEntry{},
+		&database.TableEscrow{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for userID, balance := range wallets {
+		err := db.Create(&database.Account{
+			ID:             userID,
+			Username:       userID,
+			Email:          userID + "@example.com",
+			AccountBalance: database.AccountBalance{ID: "balance-" + userID},
+		}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ledger.Adjust(db, ledger.Wallet(userID), balance, "fixture"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// ledgerState — балансы всех счетов, их проекции в кошельках и число операций
+type ledgerState struct {
+	Balances     map[string]int64
+	Wallets      map[string]int64
+	Transactions int64
+}
+
+func snapshotLedger(t *testing.T, db *gorm.DB) ledgerState {
+	t.Helper()
+	var accounts []database.LedgerAccount
+	var wallets []database.AccountBalance
+	state := ledgerState{Balances: make(map[string]int64), Wallets: make(map[string]int64)}
+	if err := db.Find(&accounts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Find(&wallets).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&database.LedgerTransaction{}).Count(&state.Transactions).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range accounts {
+		state.Balances[a.ID] = a.Balance
+	}
+	for _, w := range wallets {
+		state.Wallets[w.UserID] = w.CurrentBalance
+	}
+	return state
+}
+
+// Ретрай активности с тем же ключом идемпотентности возвращает ID первой операции
+// и не меняет ни одного баланса
+func TestChipActivitiesAreIdempotent(t *testing.T) {
+	db := ledgerFixture(t, map[string]int64{"alice": 1000, "bob": 1000})
+	activities := &RoomActivities{RoomRepo: repo.NewRoomRepo(db)}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(activities)
+
+	steps := []struct {
+		name     string
+		activity interface{}
+		input    interface{}
+	}{
+		{"buy-in alice", activities.BuyInActivity, EscrowInput{RoomID: "room", UserID: "alice", Amount: 500, Key: "room/buyin/alice"}},
+		{"buy-in bob", activities.BuyInActivity, EscrowInput{RoomID: "room", UserID: "bob", Amount: 500, Key: "room/buyin/bob"}},
+		{"settle hand", activities.SettleHandActivity, SettlementInput{
+			RoomID:  "room",
+			Hand:    1,
+			Results: map[string]int64{"alice": 100, "bob": -100},
+			Rake:    map[string]int64{"alice": 5},
+			Key:     "room/hand-1/1",
+		}},
+		{"cash-out alice", activities.CashOutActivity, EscrowInput{RoomID: "room", UserID: "alice", Amount: 595, Key: "room/cashout/alice"}},
+		{"cash-out bob", activities.CashOutActivity, EscrowInput{RoomID: "room", UserID: "bob", Amount: 400, Key: "room/cashout/bob"}},
+	}
+	for _, step := range steps {
+		var first, retry string
+		val, err := env.ExecuteActivity(step.activity, step.input)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if err := val.Get(&first); err != nil {
+			t.Fatal(err)
+		}
+		before := snapshotLedger(t, db)
+
+		val, err = env.ExecuteActivity(step.activity, step.input)
+		if err != nil {
+			t.Fatalf("%s retry: %v", step.name, err)
+		}
+		if err := val.Get(&retry); err != nil {
+			t.Fatal(err)
+		}
+		if first == "" || retry != first {
+			t.Fatalf("%s: retry returned transaction %q, first run %q", step.name, retry, first)
+		}
+		if after := snapshotLedger(t, db); !reflect.DeepEqual(after, before) {
+			t.Fatalf("%s: retry changed the ledger\nbefore: %+v\nafter:  %+v", step.name, before, after)
+		}
+	}
+
+	final := snapshotLedger(t, db)
+	want := map[string]int64{"alice": 1095, "bob": 900}
+	if !reflect.DeepEqual(final.Wallets, want) {
+		t.Fatalf("wallets = %v, want %v", final.Wallets, want)
+	}
+	if final.Balances[ledger.House] != 5 {
+		t.Fatalf("house balance = %d, want 5", final.Balances[ledger.House])
+	}
+}
+
+// Нехватку фишек и расчёт без эскроу ретрай не исправит: ошибки неповторяемые
+func TestChipActivitiesRejectDefinitively(t *testing.T) {
+	db := ledgerFixture(t, map[string]int64{"alice": 100})
+	activities := &RoomActivities{RoomRepo: repo.NewRoomRepo(db)}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(activities)
+
+	tests := []struct {
+		name     string
+		activity interface{}
+		input    EscrowInput
+	}{
+		{"insufficient funds", activities.BuyInActivity, EscrowInput{RoomID: "room", UserID: "alice", Amount: 500, Key: "room/buyin/alice"}},
+		{"zero buy-in", activities.BuyInActivity, EscrowInput{RoomID: "room", UserID: "alice", Amount: 0, Key: "room/buyin/zero"}},
+		{"no open escrow", activities.CashOutActivity, EscrowInput{RoomID: "room", UserID: "alice", Amount: 50, Key: "room/cashout/alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.ExecuteActivity(tt.activity, tt.input)
+			if err == nil {
+				t.Fatal("activity succeeded, want an error")
+			}
+			if !definitive(err) {
+				t.Fatalf("error %v is retryable", err)
+			}
+		})
+	}
+	if state := snapshotLedger(t, db); state.Wallets["alice"] != 100 {
+		t.Fatalf("alice wallet = %d, want 100", state.Wallets["alice"])
+	}
+}
+
+// chipSessionWorkflow — бай-ин, докупка и расчёт одного игрока теми же функциями, что и стол
+func chipSessionWorkflow(ctx workflow.Context) (*RoomState, error) {
+	state := restoreState(&RoomState{
+		RoomID:  "room",
+		Players: map[string]bool{"alice": true},
+		BuyIn:   BuyInLimits{Min: 100, Max: 1000},
+	})
+	if err := buyIn(ctx, state, "alice", 200); err != nil {
+		return nil, err
+	}
+	if err := buyIn(ctx, state, "alice", 300); err != nil {
+		return nil, err
+	}
+	if !cashOut(ctx, state, "alice") {
+		return nil, errors.New("cash-out failed")
+	}
+	return state, nil
+}
+
+// Активность проводит операцию и падает, не успев ответить: ретрай идёт с тем же ключом
+// и не проводит её второй раз, а следующая операция получает новый ключ
+func TestChipRetriesAfterCommitPostOnce(t *testing.T) {
+	db := ledgerFixture(t, map[string]int64{"alice": 1000})
+	activities := &RoomActivities{RoomRepo: repo.NewRoomRepo(db)}
+	before := snapshotLedger(t, db)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(chipSessionWorkflow)
+
+	var keys []string
+	failed := make(map[string]bool)
+	failAfterCommit := func(name string, run func(context.Context, EscrowInput) (string, error)) {
+		env.RegisterActivityWithOptions(func(ctx context.Context, input EscrowInput) (string, error) {
+			keys = append(keys, input.Key)
+			id, err := run(ctx, input)
+			if err != nil || failed[input.Key] {
+				return id, err
+			}
+			failed[input.Key] = true
+			return "", errors.New("connection lost after commit")
+		}, activity.RegisterOptions{Name: name})
+	}
+	failAfterCommit("BuyInActivity", activities.BuyInActivity)
+	failAfterCommit("CashOutActivity", activities.CashOutActivity)
+
+	env.ExecuteWorkflow(chipSessionWorkflow)
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("workflow did not complete")
+	}
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatal(err)
+	}
+	var state RoomState
+	if err := env.GetWorkflowResult(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	// Каждая операция — две попытки с одним ключом, у разных операций ключи разные
+	if len(keys) != 6 {
+		t.Fatalf("activity attempts = %v, want 6", keys)
+	}
+	for i := 0; i < len(keys); i += 2 {
+		if keys[i] != keys[i+1] {
+			t.Fatalf("retry used key %q, first attempt %q", keys[i+1], keys[i])
+		}
+		if i > 0 && keys[i] == keys[i-2] {
+			t.Fatalf("new operation reused key %q", keys[i])
+		}
+	}
+	for _, key := range []string{keys[0], keys[2], keys[4]} {
+		var n int64
+		if err := db.Model(&database.LedgerTransaction{}).Where("idempotency_key = ?", key).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Fatalf("key %q has %d ledger transactions, want 1", key, n)
+		}
+	}
+
+	after := snapshotLedger(t, db)
+	if after.Transactions-before.Transactions != 3 {
+		t.Fatalf("ledger gained %d transactions, want 3", after.Transactions-before.Transactions)
+	}
+	if after.Wallets["alice"] != 1000 || after.Balances[ledger.Table("room", "alice")] != 0 {
+		t.Fatalf("wallet = %d, table = %d, want 1000 and 0", after.Wallets["alice"], after.Balances[ledger.Table("room", "alice")])
+	}
+	if len(state.PlayerChips) != 0 || len(state.PendingBuyIns) != 0 || len(state.CashOutKeys) != 0 {
+		t.Fatalf("state after cash-out: chips %v, pending buy-ins %v, cash-out keys %v", state.PlayerChips, state.PendingBuyIns, state.CashOutKeys)
+	}
+}
@@ -0,0 +1,186 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/log"
+	"go.uber.org/zap"
+	"math"
+	"poker/internal/modules/room/variant"
+	"poker/packages/database"
+)
+
+// fixedLimitRaiseCap — в лимите на улице разрешены ставка и три повышения
+const fixedLimitRaiseCap = 4
+
+// roomGameType — разновидность игры комнаты; при неверном значении — холдем
+func roomGameType(room database.Room, logger log.Logger) variant.GameType {
+	t, err := variant.ParseGameType(room.GameType)
+	if err != nil {
+		logger.Warn("⚠️ Invalid game type, using default", zap.Error(err))
+		return variant.DefaultGameType
+	}
+	return t
+}
+
+// roomBettingStructure — структура ставок комнаты; при неверном значении — безлимит
+func roomBettingStructure(room database.Room, logger log.Logger) variant.BettingStructure {
+	b, err := variant.ParseBettingStructure(room.BettingStructure)
+	if err != nil {
+		logger.Warn("⚠️ Invalid betting structure, using default", zap.Error(err))
+		return variant.DefaultBettingStructure
+	}
+	return b
+}
+
+// ActionOption — доступное действие и допустимые суммы. Для bet — размер ставки,
+// для raise — на сколько повысить сверх колла, для call и allin — сколько фишек уйдёт в банк,
+// для draw — сколько карт можно сменить
+type ActionOption struct {
+	Action string `json:"action"`
+	Min    int64  `json:"min,omitempty"`
+	Max    int64  `json:"max,omitempty"`
+}
+
+// fixedBetSize — размер ставки в лимите: малая ставка на ранних улицах, большая (вдвое) — на поздних.
+// Если открывающая ставка была неполной (бринг-ин, короткий олл-ин), повышение лишь дополняет её до полной
+func fixedBetSize(state *RoomState) int64 {
+	size := state.Blinds.BigBlind
+	if street, _ := variant.Get(state.Game).Street(state.Hand.RoundStage); street.BigBet {
+		size *= 2
+	}
+	if bet := state.Hand.CurrentBet; bet > 0 && bet < size {
+		return size - bet
+	}
+	return size
+}
+
+// raiseCap — наибольшая ставка или повышение сверх колла, которое разрешает структура.
+// 0 — повышать больше нельзя
+func raiseCap(state *RoomState, userID string) int64 {
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
+	if toCall < 0 {
+		toCall = 0
+	}
+
+	if !raiseReopened(state, userID) {
+		return 0
+	}
+
+	switch state.Betting {
+	case variant.PotLimit:
+		// Банк после колла: всё, что уже в банке, плюс сам колл
+		return state.Hand.Pot + toCall
+	case variant.FixedLimit:
+		if state.Hand.Raises >= fixedLimitRaiseCap {
+			return 0
+		}
+		return fixedBetSize(state)
+	}
+	return math.MaxInt64
+}
+
+// raiseReopened — может ли игрок повышать. Кто уже ходил на этой улице, снова может повышать
+// только после полного повышения: неполный олл-ин позволяет ему лишь уравнять
+func raiseReopened(state *RoomState, userID string) bool {
+	return !state.Hand.HasActed[userID] || state.Hand.PlayerBets[userID] >= state.Hand.CurrentBet
+}
+
+// minRaise — наименьшая полная ставка или повышение
+func minRaise(state *RoomState) int64 {
+	if state.Betting == variant.FixedLimit {
+		return fixedBetSize(state)
+	}
+	if state.Hand.LastRaise > state.Blinds.BigBlind {
+		return state.Hand.LastRaise
+	}
+	return state.Blinds.BigBlind
+}
+
+// betLimits — допустимые размеры bet (если ставок ещё нет) или raise для игрока.
+// ok == false, если на полную ставку фишек не хватает: остаётся только олл-ин
+func betLimits(state *RoomState, userID string) (min, max int64, ok bool) {
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
+	if toCall < 0 {
+		toCall = 0
+	}
+	behind := state.PlayerChips[userID] - toCall
+
+	min = minRaise(state)
+	max = raiseCap(state, userID)
+	if max < min {
+		max = min
+	}
+	if max > behind {
+		max = behind
+	}
+	if raiseCap(state, userID) == 0 || behind < min {
+		return 0, 0, false
+	}
+	return min, max, true
+}
+
+// GetActionOptions — действия, доступные игроку, с допустимыми суммами.
+// Вне своего хода, после фолда или олл-ина и между раздачами действий нет
+func GetActionOptions(state *RoomState, userID string) []ActionOption {
+	if !handInProgress(state) || state.Hand.CurrentPlayer != userID || !canAct(state, userID) {
+		return nil
+	}
+
+	chips := state.PlayerChips[userID]
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
+
+	if state.Hand.Drawing {
+		return []ActionOption{{Action: "draw", Max: maxDraw}}
+	}
+
+	options := []ActionOption{{Action: "fold"}}
+
+	if toCall <= 0 {
+		options = append(options, ActionOption{Action: "check"})
+	} else if chips >= toCall {
+		options = append(options, ActionOption{Action: "call", Min: toCall, Max: toCall})
+	}
+
+	if min, max, ok := betLimits(state, userID); ok {
+		action := "raise"
+		if state.Hand.CurrentBet == 0 {
+			action = "bet"
+		}
+		options = append(options, ActionOption{Action: action, Min: min, Max: max})
+	}
+
+	if chips > 0 && allinAllowed(state, userID, chips) {
+		options = append(options, ActionOption{Action: "allin", Min: chips, Max: chips})
+	}
+	return options
+}
+
+// allinAllowed — олл-ин на amount не превышает лимит структуры ставок
+func allinAllowed(state *RoomState, userID string, amount int64) bool {
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
+	if amount <= toCall {
+		return true
+	}
+	return amount-toCall <= raiseCap(state, userID)
+}
+
+// checkBetSize проверяет размер bet или raise по структуре ставок
+func checkBetSize(state *RoomState, userID string, amount int64) error {
+	min, max, ok := betLimits(state, userID)
+	if !ok {
+		if !raiseReopened(state, userID) {
+			return fmt.Errorf("short allin did not reopen the betting, call or fold")
+		}
+		if raiseCap(state, userID) == 0 {
+			return fmt.Errorf("betting is capped on this street")
+		}
+		return fmt.Errorf("not enough chips for a full bet, use allin")
+	}
+	if amount < min || amount > max {
+		if min == max {
+			return fmt.Errorf("amount must be %d", min)
+		}
+		return fmt.Errorf("amount must be between %d and %d", min, max)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package room_temporal
+
+import (
+	"math"
+	"poker/internal/modules/room/variant"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// bettingState — холдем с блайндами 5/10 на улице street; у каждого игрока по 1000 фишек
+func bettingState(betting variant.BettingStructure, street string) *RoomState {
+	state := &RoomState{
+		Game:        variant.Holdem,
+		Betting:     betting,
+		Blinds:      Blinds{SmallBlind: 5, BigBlind: 10},
+		PlayerChips: map[string]int64{"a": 1000, "b": 1000, "c": 1000},
+		Hand:        newHand(1, time.Time{}, street),
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		state.Hand.PlayerOrder = append(state.Hand.PlayerOrder, id)
+		state.Hand.PlayerFolded[id] = false
+		state.Hand.PlayerAllIn[id] = false
+	}
+	return state
+}
+
+func TestPotLimitRaiseCap(t *testing.T) {
+	// Префлоп: в банке блайнды 5 и 10, a должен 10 — повысить можно на банк после колла
+	state := bettingState(variant.PotLimit, "preflop")
+	state.Hand.Pot = 15
+	state.Hand.CurrentBet = 10
+	state.Hand.LastRaise = 10
+	state.Hand.PlayerBets["b"] = 5
+	state.Hand.PlayerBets["c"] = 10
+
+	if got := raiseCap(state, "a"); got != 25 {
+		t.Fatalf("preflop raise cap = %d, want pot 15 + call 10 = 25", got)
+	}
+	if min, max, ok := betLimits(state, "a"); !ok || min != 10 || max != 25 {
+		t.Fatalf("preflop limits = %d..%d (%v), want 10..25", min, max, ok)
+	}
+	// У блайнда колл меньше — и потолок тоже
+	if got := raiseCap(state, "b"); got != 20 {
+		t.Fatalf("small blind raise cap = %d, want 15 + 5 = 20", got)
+	}
+
+	// Флоп: в банке 100, b поставил 50; у c за коллом лишь 70 — максимум ограничен стеком
+	state = bettingState(variant.PotLimit, "flop")
+	state.Hand.Pot = 150
+	state.Hand.CurrentBet = 50
+	state.Hand.LastRaise = 50
+	state.Hand.PlayerBets["b"] = 50
+	state.Hand.HasActed["b"] = true
+	state.PlayerChips["c"] = 120
+	if got := raiseCap(state, "c"); got != 200 {
+		t.Fatalf("flop raise cap = %d, want 150 + 50 = 200", got)
+	}
+	if min, max, ok := betLimits(state, "c"); !ok || min != 50 || max != 70 {
+		t.Fatalf("short stack limits = %d..%d (%v), want 50..70", min, max, ok)
+	}
+
+	if got := raiseCap(bettingState(variant.NoLimit, "flop"), "a"); got != math.MaxInt64 {
+		t.Fatalf("no-limit raise cap = %d, want unlimited", got)
+	}
+}
+
+func TestFixedLimitBets(t *testing.T) {
+	tests := []struct {
+		street     string
+		currentBet int64
+		raises     int
+		wantMin    int64
+		wantOK     bool
+	}{
+		{"preflop", 10, 1, 10, true}, // малая ставка
+		{"flop", 0, 0, 10, true},
+		{"turn", 0, 0, 20, true}, // большая ставка
+		{"river", 60, 3, 20, true},
+		{"flop", 40, fixedLimitRaiseCap, 0, false}, // ставка и три повышения — кэп
+		{"river", 80, fixedLimitRaiseCap, 0, false},
+	}
+	for _, tt := range tests {
+		state := bettingState(variant.FixedLimit, tt.street)
+		state.Hand.CurrentBet = tt.currentBet
+		state.Hand.Raises = tt.raises
+		state.Hand.PlayerBets["b"] = tt.currentBet
+
+		min, max, ok := betLimits(state, "a")
+		if ok != tt.wantOK || min != tt.wantMin || max != tt.wantMin {
+			t.Errorf("%s with %d raises: limits %d..%d (%v), want exactly %d (%v)",
+				tt.street, tt.raises, min, max, ok, tt.wantMin, tt.wantOK)
+		}
+	}
+
+	// Неполная открывающая ставка дополняется до полной, а не удваивается
+	state := bettingState(variant.FixedLimit, "flop")
+	state.Hand.CurrentBet = 3
+	if got := fixedBetSize(state); got != 7 {
+		t.Fatalf("completion after a bet of 3 = %d, want 7", got)
+	}
+}
+
+// Неполный олл-ин не даёт повысить тому, кто уже ходил на улице, но не мешает тем,
+// кто ещё не ходил
+func TestShortAllinDoesNotReopenRaising(t *testing.T) {
+	state := bettingState(variant.NoLimit, "flop")
+	state.Hand.Pot = 250
+	// a поставил 100, b пошёл олл-ин на 150 — повышение на 50 меньше минимального
+	state.Hand.PlayerBets["a"] = 100
+	state.Hand.PlayerBets["b"] = 150
+	state.Hand.HasActed["a"] = true
+	state.Hand.HasActed["b"] = true
+	state.Hand.PlayerAllIn["b"] = true
+	state.Hand.CurrentBet = 150
+	state.Hand.LastRaise = 100
+	state.PlayerChips["b"] = 0
+
+	if raiseReopened(state, "a") {
+		t.Fatal("short allin reopened raising for a")
+	}
+	if _, _, ok := betLimits(state, "a"); ok {
+		t.Fatal("a may raise after a short allin")
+	}
+	if err := checkBetSize(state, "a", 100); err == nil {
+		t.Fatal("checkBetSize accepted a raise after a short allin")
+	}
+	state.Hand.CurrentPlayer = "a"
+	want := []ActionOption{{Action: "fold"}, {Action: "call", Min: 50, Max: 50}}
+	if got := GetActionOptions(state, "a"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("options for a = %+v, want %+v", got, want)
+	}
+
+	if !raiseReopened(state, "c") {
+		t.Fatal("c has not acted yet and must be able to raise")
+	}
+	if min, _, ok := betLimits(state, "c"); !ok || min != 100 {
+		t.Fatalf("c min raise = %d (%v), want 100", min, ok)
+	}
+}
@@ -0,0 +1,257 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/evaluator"
+	"poker/internal/modules/room/variant"
+	"poker/packages/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLimits используется, если у комнаты не заданы лимиты
+const DefaultLimits = "1/2"
+
+// Blinds — обязательные ставки стола
+type Blinds struct {
+	SmallBlind int64
+	BigBlind   int64
+	Ante       int64
+}
+
+// ParseLimits разбирает Room.Limits вида "1/2" или "1/2/1" (малый блайнд / большой блайнд / анте)
+func ParseLimits(limits string) (Blinds, error) {
+	limits = strings.TrimSpace(limits)
+	if limits == "" {
+		limits = DefaultLimits
+	}
+
+	parts := strings.Split(limits, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Blinds{}, fmt.Errorf("invalid limits %q: expected SB/BB or SB/BB/ante", limits)
+	}
+
+	values := make([]int64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+		if err != nil || v < 0 {
+			return Blinds{}, fmt.Errorf("invalid limits %q: %q is not a valid amount", limits, p)
+		}
+		values[i] = v
+	}
+
+	b := Blinds{SmallBlind: values[0], BigBlind: values[1]}
+	if len(values) == 3 {
+		b.Ante = values[2]
+	}
+
+	if b.BigBlind <= 0 {
+		return Blinds{}, fmt.Errorf("invalid limits %q: big blind must be positive", limits)
+	}
+	if b.SmallBlind > b.BigBlind {
+		return Blinds{}, fmt.Errorf("invalid limits %q: small blind is bigger than big blind", limits)
+	}
+	return b, nil
+}
+
+// nextPlayer возвращает первого игрока после from (по кругу), для которого ok == true
+func nextPlayer(order []string, from string, ok func(id string) bool) string {
+	n := len(order)
+	start := -1
+	for i, id := range order {
+		if id == from {
+			start = i
+			break
+		}
+	}
+	for i := 1; i <= n; i++ {
+		id := order[(start+i+n)%n]
+		if ok(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+func anyPlayer(string) bool { return true }
+
+// moveButton передвигает баттон по местам на следующего участника раздачи
+func moveButton(state *RoomState) {
+	inHand := make(map[string]bool, len(state.Hand.PlayerOrder))
+	for _, id := range state.Hand.PlayerOrder {
+		inHand[id] = true
+	}
+	state.Dealer = nextPlayer(state.Seats, state.Dealer, func(id string) bool { return inHand[id] })
+}
+
+// blindPositions возвращает малый и большой блайнды. В хедз-апе малый блайнд ставит баттон
+func blindPositions(state *RoomState) (string, string) {
+	if len(state.Hand.PlayerOrder) == 2 {
+		return state.Dealer, nextPlayer(state.Hand.PlayerOrder, state.Dealer, anyPlayer)
+	}
+	sb := nextPlayer(state.Hand.PlayerOrder, state.Dealer, anyPlayer)
+	return sb, nextPlayer(state.Hand.PlayerOrder, sb, anyPlayer)
+}
+
+// commitChips переносит фишки игрока в банк; если фишек не хватает — игрок в олл-ине
+func commitChips(state *RoomState, userID string, amount int64, live bool) int64 {
+	if amount > state.PlayerChips[userID] {
+		amount = state.PlayerChips[userID]
+	}
+	state.PlayerChips[userID] -= amount
+	state.Hand.Pot += amount
+	state.Hand.Contributions[userID] += amount
+	if live {
+		state.Hand.PlayerBets[userID] += amount
+	}
+	if state.PlayerChips[userID] == 0 {
+		state.Hand.PlayerAllIn[userID] = true
+	}
+	return amount
+}
+
+// postBlinds ставит анте и блайнды и передаёт ход первому игроку префлопа.
+// Возвращает, сколько фишек поставил каждый игрок.
+func postBlinds(state *RoomState) map[string]int64 {
+	posted := make(map[string]int64)
+
+	if state.Blinds.Ante > 0 {
+		for _, id := range state.Hand.PlayerOrder {
+			ante := commitChips(state, id, state.Blinds.Ante, false)
+			posted[id] += ante
+			recordAction(state, HandAction{UserID: id, Action: "ante", Amount: ante, At: state.Hand.StartTime})
+		}
+	}
+
+	// В стаде блайндов нет — бринг-ин ставится после раздачи
+	if variant.Get(state.Game).Stud {
+		return posted
+	}
+
+	sb, bb := blindPositions(state)
+	small := commitChips(state, sb, state.Blinds.SmallBlind, true)
+	recordAction(state, HandAction{UserID: sb, Action: "small-blind", Amount: small, At: state.Hand.StartTime})
+	big := commitChips(state, bb, state.Blinds.BigBlind, true)
+	recordAction(state, HandAction{UserID: bb, Action: "big-blind", Amount: big, At: state.Hand.StartTime})
+	posted[sb] += small
+	posted[bb] += big
+
+	state.Hand.SmallBlindPlayer = sb
+	state.Hand.BigBlindPlayer = bb
+	state.Hand.CurrentBet = state.Blinds.BigBlind
+	state.Hand.LastRaise = state.Blinds.BigBlind
+	state.Hand.Raises = 1 // большой блайнд считается ставкой
+
+	// Префлоп начинает игрок слева от большого блайнда (в хедз-апе — баттон)
+	state.Hand.CurrentPlayer = nextPlayer(state.Hand.PlayerOrder, bb, func(id string) bool {
+		return canAct(state, id)
+	})
+	return posted
+}
+
+// startBettingRound сбрасывает ставки улицы; постфлоп первым ходит игрок слева от баттона
+func startBettingRound(state *RoomState) {
+	state.Hand.CurrentBet = 0
+	state.Hand.LastRaise = 0
+	state.Hand.Raises = 0
+	state.Hand.PlayerBets = make(map[string]int64)
+	state.Hand.HasActed = make(map[string]bool)
+	if variant.Get(state.Game).Stud {
+		state.Hand.CurrentPlayer = bestVisibleHand(state)
+		return
+	}
+	state.Hand.CurrentPlayer = nextPlayer(state.Hand.PlayerOrder, state.Dealer, func(id string) bool {
+		return canAct(state, id)
+	})
+}
+
+// studSuitOrder — старшинство мастей при равных рангах для бринг-ина: трефы младше всех, пики старше
+var studSuitOrder = [cards.NumSuits]int{cards.Spades: 3, cards.Hearts: 2, cards.Diamonds: 1, cards.Clubs: 0}
+
+// postBringIn — в стаде игрок с младшей открытой картой ставит бринг-ин (малый блайнд лимитов),
+// ход переходит к следующему. Бринг-ин не даёт права хода, если никто не повысил
+func postBringIn(state *RoomState) (string, int64) {
+	var (
+		bringIn string
+		lowest  cards.Card
+	)
+	for _, id := range state.Hand.PlayerOrder {
+		up := state.Hand.UpCards[id]
+		if !canAct(state, id) || len(up) == 0 {
+			continue
+		}
+		c := up[len(up)-1]
+		if bringIn == "" || c.Rank() < lowest.Rank() ||
+			(c.Rank() == lowest.Rank() && studSuitOrder[c.Suit()] < studSuitOrder[lowest.Suit()]) {
+			bringIn, lowest = id, c
+		}
+	}
+	if bringIn == "" {
+		return "", 0
+	}
+
+	amount := commitChips(state, bringIn, state.Blinds.SmallBlind, true)
+	state.Hand.BringInPlayer = bringIn
+	recordAction(state, HandAction{UserID: bringIn, Action: "bring-in", Amount: amount, At: state.Hand.StartTime})
+	if bet := state.Hand.PlayerBets[bringIn]; bet > state.Hand.CurrentBet {
+		state.Hand.CurrentBet = bet
+	}
+	state.Hand.LastRaise = amount
+	state.Hand.HasActed[bringIn] = true
+	state.Hand.CurrentPlayer = nextPlayer(state.Hand.PlayerOrder, bringIn, func(id string) bool {
+		return canAct(state, id)
+	})
+	return bringIn, amount
+}
+
+// bestVisibleHand — в стаде первым ходит лучшая открытая рука; при равенстве — ближайший слева от баттона
+func bestVisibleHand(state *RoomState) string {
+	var (
+		best      string
+		bestValue evaluator.HandValue
+	)
+	id := state.Dealer
+	for range state.Hand.PlayerOrder {
+		id = nextPlayer(state.Hand.PlayerOrder, id, anyPlayer)
+		if !canAct(state, id) {
+			continue
+		}
+		if v := evaluator.Evaluate(state.Hand.UpCards[id]); best == "" || v > bestValue {
+			best, bestValue = id, v
+		}
+	}
+	return best
+}
+
+// canAct — игрок ещё может делать ставки в этой раздаче
+func canAct(state *RoomState, userID string) bool {
+	return !state.Hand.PlayerFolded[userID] && !state.Hand.PlayerAllIn[userID]
+}
+
+// loadRoom подгружает настройки комнаты из базы
+func loadRoom(ctx workflow.Context, roomID string, logger log.Logger) database.Room {
+	actCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Second,
+	})
+
+	var room database.Room
+	if err := workflow.ExecuteActivity(actCtx, GetRoomActivity, roomID).Get(actCtx, &room); err != nil {
+		logger.Error("❌ Failed to load room", zap.String("roomID", roomID), zap.Error(err))
+	}
+	return room
+}
+
+// roomBlinds — блайнды комнаты; при неверных лимитах используются DefaultLimits
+func roomBlinds(room database.Room, logger log.Logger) Blinds {
+	blinds, err := ParseLimits(room.Limits)
+	if err != nil {
+		logger.Warn("⚠️ Invalid room limits, using defaults", zap.String("limits", room.Limits), zap.Error(err))
+		blinds, _ = ParseLimits(DefaultLimits)
+	}
+	return blinds
+}
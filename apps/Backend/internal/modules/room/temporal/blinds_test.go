@@ -0,0 +1,85 @@
+package room_temporal
+
+import (
+	"poker/internal/modules/room/variant"
+	"testing"
+	"time"
+)
+
+// studState — стад с лимитами 5/10 (бринг-ин 5), у игроков открыты карты up
+func studState(t *testing.T, street, dealer string, up map[string]string) *RoomState {
+	t.Helper()
+	state := &RoomState{
+		Game:        variant.Stud,
+		Betting:     variant.FixedLimit,
+		Blinds:      Blinds{SmallBlind: 5, BigBlind: 10},
+		Dealer:      dealer,
+		PlayerChips: make(map[string]int64),
+		Hand:        newHand(1, time.Time{}, street),
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if _, ok := up[id]; !ok {
+			continue
+		}
+		state.Hand.PlayerOrder = append(state.Hand.PlayerOrder, id)
+		state.Hand.PlayerFolded[id] = false
+		state.Hand.PlayerAllIn[id] = false
+		state.Hand.UpCards[id] = parseCards(t, up[id])
+		state.PlayerChips[id] = 1000
+	}
+	return state
+}
+
+func TestPostBringIn(t *testing.T) {
+	// Двойки у b и c: младшая масть — бубны, поэтому бринг-ин ставит b
+	state := studState(t, "third", "a", map[string]string{"a": "Ks", "b": "2d", "c": "2s", "d": "2h"})
+	bringIn, amount := postBringIn(state)
+	if bringIn != "b" || amount != 5 {
+		t.Fatalf("bring-in = %s for %d, want b for 5", bringIn, amount)
+	}
+	if state.Hand.CurrentBet != 5 || state.Hand.PlayerBets["b"] != 5 || state.PlayerChips["b"] != 995 {
+		t.Fatalf("after bring-in: current bet %d, b bet %d, b stack %d", state.Hand.CurrentBet, state.Hand.PlayerBets["b"], state.PlayerChips["b"])
+	}
+	if state.Hand.CurrentPlayer != "c" {
+		t.Fatalf("first to act after the bring-in = %s, want c", state.Hand.CurrentPlayer)
+	}
+
+	// Короткий стек ставит бринг-ин на всё, что есть, и уходит в олл-ин; сбросивший не в счёт
+	state = studState(t, "third", "a", map[string]string{"a": "3c", "b": "4d", "c": "2c"})
+	state.Hand.PlayerFolded["c"] = true
+	state.PlayerChips["a"] = 3
+	bringIn, amount = postBringIn(state)
+	if bringIn != "a" || amount != 3 || !state.Hand.PlayerAllIn["a"] {
+		t.Fatalf("short bring-in = %s for %d (all-in %v), want a all-in for 3", bringIn, amount, state.Hand.PlayerAllIn["a"])
+	}
+	if state.Hand.CurrentPlayer != "b" {
+		t.Fatalf("first to act = %s, want b", state.Hand.CurrentPlayer)
+	}
+}
+
+func TestBestVisibleHand(t *testing.T) {
+	tests := []struct {
+		name   string
+		dealer string
+		up     map[string]string
+		folded string
+		want   string
+	}{
+		{"open pair beats ace-king", "a", map[string]string{"a": "Ks 9d", "b": "9h 9c", "c": "Ah Kd"}, "", "b"},
+		{"folded pair is skipped", "a", map[string]string{"a": "Ks 9d", "b": "9h 9c", "c": "Ah Kd"}, "b", "c"},
+		// Равные руки у a и c: первым ходит ближайший слева от баттона
+		{"tie goes left of the button", "a", map[string]string{"a": "Ks Qd", "b": "7h 4c", "c": "Kh Qc"}, "", "c"},
+		{"tie wraps around the button", "c", map[string]string{"a": "Ks Qd", "b": "7h 4c", "c": "Kh Qc"}, "", "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := studState(t, "fourth", tt.dealer, tt.up)
+			if tt.folded != "" {
+				state.Hand.PlayerFolded[tt.folded] = true
+			}
+			if got := bestVisibleHand(state); got != tt.want {
+				t.Fatalf("first to act = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
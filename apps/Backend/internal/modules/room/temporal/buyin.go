@@ -0,0 +1,276 @@
+package room_temporal
+
+import (
+	"errors"
+	"fmt"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+	"poker/packages/database"
+	"time"
+)
+
+// Бай-ин по умолчанию — от 20 до 100 больших блайндов
+const (
+	defaultMinBuyInBB = 20
+	defaultMaxBuyInBB = 100
+)
+
+// BuyInLimits — сколько фишек игрок может принести за стол
+type BuyInLimits struct {
+	Min int64
+	Max int64
+}
+
+type BuyInSignal struct {
+	UserID string
+	Amount int64
+}
+
+// ParseBuyIn проверяет лимиты бай-ина; 0 — значение по умолчанию в больших блайндах
+func ParseBuyIn(min, max int64, blinds Blinds) (BuyInLimits, error) {
+	l := BuyInLimits{Min: min, Max: max}
+	if l.Min == 0 {
+		l.Min = defaultMinBuyInBB * blinds.BigBlind
+	}
+	if l.Max == 0 {
+		l.Max = defaultMaxBuyInBB * blinds.BigBlind
+		if l.Max < l.Min {
+			l.Max = l.Min
+		}
+	}
+
+	if l.Min < blinds.BigBlind {
+		return BuyInLimits{}, fmt.Errorf("invalid buy-in %d: must be at least the big blind (%d)", l.Min, blinds.BigBlind)
+	}
+	if l.Max < l.Min {
+		return BuyInLimits{}, fmt.Errorf("invalid buy-in: max %d is less than min %d", l.Max, l.Min)
+	}
+	return l, nil
+}
+
+// roomBuyIn — лимиты бай-ина комнаты; при неверных значениях — по умолчанию
+func roomBuyIn(room database.Room, blinds Blinds, logger log.Logger) BuyInLimits {
+	l, err := ParseBuyIn(room.MinBuyIn, room.MaxBuyIn, blinds)
+	if err != nil {
+		logger.Warn("⚠️ Invalid room buy-in, using defaults", zap.Error(err))
+		l, _ = ParseBuyIn(0, 0, blinds)
+	}
+	return l
+}
+
+// buyIn переносит фишки из кошелька в эскроу стола. Первый бай-ин — от Min до Max,
+// докупка — пока стек не больше Max. Во время своей раздачи докупаться нельзя
+func buyIn(ctx workflow.Context, state *RoomState, userID string, amount int64) error {
+	if !state.Players[userID] {
+		return fmt.Errorf("player %s not in room", userID)
+	}
+	if _, inHand := state.Hand.PlayerFolded[userID]; inHand && handInProgress(state) {
+		return fmt.Errorf("wait for the hand to finish")
+	}
+	if _, pending := state.CashOutKeys[userID]; pending {
+		return fmt.Errorf("previous cash-out is still in progress")
+	}
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	// Бай-ин без окончательного ответа повторяется только с той же суммой и тем же ключом:
+	// если операция уже прошла, книга вернёт её, а не проведёт вторую
+	input, pending := state.PendingBuyIns[userID]
+	if pending && input.Amount != amount {
+		return fmt.Errorf("previous buy-in of %d is still in progress, repeat it with the same amount", input.Amount)
+	}
+
+	stack := state.PlayerChips[userID]
+	if stack == 0 && amount < state.BuyIn.Min {
+		return fmt.Errorf("buy-in must be between %d and %d", state.BuyIn.Min, state.BuyIn.Max)
+	}
+	if stack+amount > state.BuyIn.Max {
+		return fmt.Errorf("stack would exceed the max buy-in %d, you can add at most %d", state.BuyIn.Max, state.BuyIn.Max-stack)
+	}
+
+	if !pending {
+		state.PendingBuyIns[userID] = EscrowInput{RoomID: state.RoomID, UserID: userID, Amount: amount, Key: ledgerKey(ctx, state)}
+	}
+	err := confirmBuyIn(ctx, state, userID)
+	if err != nil && definitive(err) {
+		return fmt.Errorf("buy-in failed, check your balance")
+	}
+	if err != nil {
+		return fmt.Errorf("buy-in is not confirmed yet, repeat it with the same amount")
+	}
+	return nil
+}
+
+// confirmBuyIn проводит ожидающий бай-ин игрока и кладёт фишки в стек. Ключ забывается
+// только при окончательном ответе: успехе или отказе, который ретрай не исправит
+func confirmBuyIn(ctx workflow.Context, state *RoomState, userID string) error {
+	input := state.PendingBuyIns[userID]
+	actCtx := workflow.WithActivityOptions(ctx, chipActivityOptions)
+	err := workflow.ExecuteActivity(actCtx, BuyInActivity, input).Get(actCtx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("❌ Buy-in failed", zap.String("userID", userID), zap.Error(err))
+		if definitive(err) {
+			delete(state.PendingBuyIns, userID)
+		}
+		return err
+	}
+	delete(state.PendingBuyIns, userID)
+	state.PlayerChips[userID] += input.Amount
+	return nil
+}
+
+// cashOut — расчёт: весь оставшийся стек возвращается из эскроу в кошелёк одной операцией.
+// Стек снимается со стола только после успешной операции; при ошибке он остаётся,
+// а следующая попытка идёт с тем же ключом и не вернёт фишки дважды.
+// Неподтверждённый бай-ин сначала доводится до конца, иначе его фишки останутся в эскроу,
+// а стек снимается, только когда последняя раздача проведена в книге
+func cashOut(ctx workflow.Context, state *RoomState, userID string) bool {
+	if !settlePending(ctx, state) {
+		return false
+	}
+	if _, pending := state.PendingBuyIns[userID]; pending {
+		if err := confirmBuyIn(ctx, state, userID); err != nil && !definitive(err) {
+			return false
+		}
+	}
+	stack, boughtIn := state.PlayerChips[userID]
+	if !boughtIn {
+		return true
+	}
+	key, pending := state.CashOutKeys[userID]
+	if !pending {
+		key = ledgerKey(ctx, state)
+		state.CashOutKeys[userID] = key
+	}
+
+	actCtx := workflow.WithActivityOptions(ctx, chipActivityOptions)
+	err := workflow.ExecuteActivity(actCtx, CashOutActivity, EscrowInput{
+		RoomID: state.RoomID,
+		UserID: userID,
+		Amount: stack,
+		Key:    key,
+	}).Get(actCtx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("❌ Cash-out failed", zap.String("userID", userID), zap.Int64("amount", stack), zap.Error(err))
+		// Операция точно не прошла: следующая попытка — новая операция со своим ключом
+		if definitive(err) {
+			delete(state.CashOutKeys, userID)
+		}
+		return false
+	}
+	delete(state.PlayerChips, userID)
+	delete(state.CashOutKeys, userID)
+	workflow.GetLogger(ctx).Info("💰 Cashed out", zap.String("userID", userID), zap.Int64("amount", stack))
+	return true
+}
+
+// chipActivityOptions — операции с фишками: ограниченное число попыток, чтобы ошибка
+// не держала воркфлоу стола бесконечно
+var chipActivityOptions = workflow.ActivityOptions{
+	StartToCloseTimeout: 5 * time.Second,
+	RetryPolicy: &temporal.RetryPolicy{
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2.0,
+		MaximumAttempts:    3,
+	},
+}
+
+// definitive — операция отклонена окончательно и не была проведена: нехватка фишек,
+// нет эскроу, неверный ввод. После таймаута или исчерпанных попыток исход неизвестен
+func definitive(err error) bool {
+	var appErr *temporal.ApplicationError
+	return errors.As(err, &appErr) && appErr.NonRetryable()
+}
+
+// ledgerKey — ключ идемпотентности очередной операции с фишками: воркфлоу, раздача и номер
+// операции. При реплее воркфлоу ключи те же, поэтому ретрай не проведёт операцию дважды
+func ledgerKey(ctx workflow.Context, state *RoomState) string {
+	state.LedgerSeq++
+	return fmt.Sprintf("%s/hand-%d/%d", workflow.GetInfo(ctx).WorkflowExecution.ID, state.HandNumber, state.LedgerSeq)
+}
+
+// settleHand проводит итог раздачи по счетам игроков за столом в книге фишек,
+// рейк — отдельной операцией на счёт заведения
+func settleHand(ctx workflow.Context, state *RoomState) {
+	results := make(map[string]int64)
+	for id, amount := range state.Hand.Contributions {
+		results[id] -= amount
+	}
+	for _, w := range state.Hand.Winners {
+		results[w.UserID] += w.Amount
+	}
+	// Рейк платят победители из выигрыша: в книге они сначала получают банк целиком
+	rake := rakeShares(state.Hand.Results)
+	for id, amount := range rake {
+		results[id] += amount
+	}
+
+	state.PendingSettlement = &SettlementInput{
+		RoomID:  state.RoomID,
+		Hand:    state.Hand.Number,
+		Results: results,
+		Rake:    rake,
+		Key:     ledgerKey(ctx, state),
+	}
+	settlePending(ctx, state)
+}
+
+// settlePending проводит ожидающий расчёт раздачи с его ключом. Пока расчёт не подтверждён,
+// стеки за столом расходятся с книгой: новые раздачи не сдаются и расчёты с игроками не идут.
+// Если книга отказала окончательно, операция не проведена — раздача отменяется и стеки
+// возвращаются к началу раздачи
+func settlePending(ctx workflow.Context, state *RoomState) bool {
+	input := state.PendingSettlement
+	if input == nil {
+		return true
+	}
+	logger := workflow.GetLogger(ctx)
+
+	actCtx := workflow.WithActivityOptions(ctx, chipActivityOptions)
+	err := workflow.ExecuteActivity(actCtx, SettleHandActivity, *input).Get(actCtx, nil)
+	if err == nil {
+		state.PendingSettlement = nil
+		return true
+	}
+	logger.Error("❌ Hand settlement failed", zap.Int("hand", input.Hand), zap.Error(err))
+	if !definitive(err) {
+		return false
+	}
+
+	for id, amount := range input.Results {
+		state.PlayerChips[id] -= amount - input.Rake[id]
+	}
+	state.PendingSettlement = nil
+	sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("↩️ Hand #%d could not be settled and was voided, stacks restored", input.Hand))
+	return true
+}
+
+// settledOrHalt не даёт сдать раздачу, пока прошлая не проведена в книге: стол встаёт на паузу,
+// а расчёт повторяется с тем же ключом при следующем старте
+func settledOrHalt(ctx workflow.Context, state *RoomState) bool {
+	if settlePending(ctx, state) {
+		return true
+	}
+	state.GameStarted = false
+	sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("⛔ Hand #%d is not settled yet — table paused, start again to retry", state.PendingSettlement.Hand))
+	return false
+}
+
+// returnPot возвращает игрокам их вклады в банк недоигранной раздачи
+func returnPot(state *RoomState) {
+	for id, amount := range state.Hand.Contributions {
+		state.PlayerChips[id] += amount
+	}
+	state.Hand.Pot = 0
+	state.Hand.Contributions = make(map[string]int64)
+}
+
+// cashOutAll рассчитывает всех игроков при закрытии стола
+func cashOutAll(ctx workflow.Context, state *RoomState) {
+	for _, id := range state.Seats {
+		cashOut(ctx, state, id)
+	}
+}
@@ -0,0 +1,205 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+	"poker/packages/database"
+	"time"
+)
+
+const (
+	DefaultActionTimeout = 30 * time.Second
+	DefaultTimeBank      = 60 * time.Second
+	MaxActionTimeout     = 5 * time.Minute
+	MaxTimeBank          = 10 * time.Minute
+
+	minActionTimeout = 5 * time.Second
+	timeBankRefill   = 10 * time.Second // пополнение банка времени перед каждой раздачей
+)
+
+// ClockSettings — сколько времени даётся на ход и какой банк времени у игрока
+type ClockSettings struct {
+	ActionTimeout time.Duration
+	TimeBank      time.Duration
+}
+
+// ParseClock собирает настройки из секунд; 0 — значение по умолчанию
+func ParseClock(actionTimeout, timeBank int) (ClockSettings, error) {
+	c := ClockSettings{
+		ActionTimeout: time.Duration(actionTimeout) * time.Second,
+		TimeBank:      time.Duration(timeBank) * time.Second,
+	}
+	if actionTimeout == 0 {
+		c.ActionTimeout = DefaultActionTimeout
+	}
+	if timeBank == 0 {
+		c.TimeBank = DefaultTimeBank
+	}
+
+	if c.ActionTimeout < minActionTimeout || c.ActionTimeout > MaxActionTimeout {
+		return ClockSettings{}, fmt.Errorf("invalid action timeout %ds: expected %d..%d seconds",
+			actionTimeout, int(minActionTimeout.Seconds()), int(MaxActionTimeout.Seconds()))
+	}
+	if c.TimeBank < 0 || c.TimeBank > MaxTimeBank {
+		return ClockSettings{}, fmt.Errorf("invalid time bank %ds: expected 0..%d seconds",
+			timeBank, int(MaxTimeBank.Seconds()))
+	}
+	return c, nil
+}
+
+// roomClock — настройки часов комнаты; при неверных значениях используются значения по умолчанию
+func roomClock(room database.Room, logger log.Logger) ClockSettings {
+	c, err := ParseClock(room.ActionTimeout, room.TimeBank)
+	if err != nil {
+		logger.Warn("⚠️ Invalid room clock, using defaults", zap.Error(err))
+		c, _ = ParseClock(0, 0)
+	}
+	return c
+}
+
+// TurnClock — часы текущего хода
+type TurnClock struct {
+	Player        string
+	Deadline      time.Time
+	UsingTimeBank bool
+	BankStarted   time.Time
+}
+
+// refillTimeBank пополняет банк времени игрока; новичок получает полный банк
+func refillTimeBank(state *RoomState, userID string) {
+	bank, ok := state.TimeBanks[userID]
+	if !ok {
+		state.TimeBanks[userID] = state.Clock.TimeBank
+		return
+	}
+	bank += timeBankRefill
+	if bank > state.Clock.TimeBank {
+		bank = state.Clock.TimeBank
+	}
+	state.TimeBanks[userID] = bank
+}
+
+// spendTimeBank списывает из банка время, потраченное на ход сверх основного
+func spendTimeBank(ctx workflow.Context, state *RoomState, userID string) {
+	clock := state.Hand.Clock
+	if !clock.UsingTimeBank || clock.Player != userID {
+		return
+	}
+	bank := state.TimeBanks[userID] - workflow.Now(ctx).Sub(clock.BankStarted)
+	if bank < 0 {
+		bank = 0
+	}
+	state.TimeBanks[userID] = bank
+}
+
+// actionClock — таймер хода в воркфлоу. Ход определяется раздачей, игроком
+// и числом записей в логе, так что любое действие перезапускает таймер
+type actionClock struct {
+	turn   string
+	timer  workflow.Future
+	cancel workflow.CancelFunc
+}
+
+func turnKey(state *RoomState) string {
+	if !handInProgress(state) || state.Hand.CurrentPlayer == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d/%s/%d", state.HandNumber, state.Hand.CurrentPlayer, len(state.Hand.MoveLog))
+}
+
+// sync запускает таймер для нового хода и отменяет таймер прошедшего
+func (c *actionClock) sync(ctx workflow.Context, state *RoomState) {
+	turn := turnKey(state)
+	if turn == c.turn {
+		return
+	}
+	c.stop()
+	c.turn = turn
+	state.Hand.Clock = TurnClock{}
+	if turn == "" {
+		return
+	}
+
+	c.start(ctx, state.Clock.ActionTimeout)
+	state.Hand.Clock = TurnClock{
+		Player:   state.Hand.CurrentPlayer,
+		Deadline: workflow.Now(ctx).Add(state.Clock.ActionTimeout),
+	}
+}
+
+func (c *actionClock) start(ctx workflow.Context, d time.Duration) {
+	timerCtx, cancel := workflow.WithCancel(ctx)
+	c.timer = workflow.NewTimer(timerCtx, d)
+	c.cancel = cancel
+}
+
+func (c *actionClock) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.timer = nil
+	c.cancel = nil
+}
+
+// expire — время хода вышло: сначала идёт банк времени, затем авто-чек или авто-фолд
+// (на обмене игрок остаётся при своих картах)
+func (c *actionClock) expire(ctx workflow.Context, state *RoomState) {
+	c.timer = nil
+	c.cancel = nil
+
+	userID := state.Hand.CurrentPlayer
+	if turnKey(state) != c.turn || userID == "" {
+		return
+	}
+
+	if bank := state.TimeBanks[userID]; !state.Hand.Clock.UsingTimeBank && bank > 0 {
+		now := workflow.Now(ctx)
+		state.Hand.Clock.UsingTimeBank = true
+		state.Hand.Clock.BankStarted = now
+		state.Hand.Clock.Deadline = now.Add(bank)
+		c.start(ctx, bank)
+		sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("⏳ %s is using time bank (%ds)", userID, int(bank.Seconds())))
+		return
+	}
+	if state.Hand.Clock.UsingTimeBank {
+		state.TimeBanks[userID] = 0
+	}
+
+	action := "fold"
+	switch {
+	case state.Hand.Drawing:
+		action = "draw" // без аргументов — игрок стоит на своих картах
+	case ActionRegistry["check"].Validate(state, userID, nil) == nil:
+		action = "check"
+	}
+	workflow.GetLogger(ctx).Info("⌛ Turn timed out", zap.String("userID", userID), zap.String("action", action))
+	sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("⌛ %s ran out of time", userID))
+	applyMove(ctx, state, userID, action, nil)
+}
+
+// clockPayload — оставшееся время хода на момент now для клиентов
+func clockPayload(state RoomState, now time.Time) map[string]interface{} {
+	clock := state.Hand.Clock
+	if clock.Player == "" {
+		return nil
+	}
+
+	timeLeft := clock.Deadline.Sub(now)
+	if timeLeft < 0 {
+		timeLeft = 0
+	}
+	banks := make(map[string]int64, len(state.TimeBanks))
+	for id, bank := range state.TimeBanks {
+		banks[id] = int64(bank.Seconds())
+	}
+	return map[string]interface{}{
+		"player":        clock.Player,
+		"deadline":      clock.Deadline.UnixMilli(),
+		"timeLeft":      timeLeft.Milliseconds(),
+		"usingTimeBank": clock.UsingTimeBank,
+		"actionTimeout": int64(state.Clock.ActionTimeout.Seconds()),
+		"timeBanks":     banks,
+	}
+}
@@ -0,0 +1,62 @@
+package room_temporal
+
+import (
+	"go.temporal.io/sdk/workflow"
+	"time"
+)
+
+// Стол живёт долго, а история одного запуска воркфлоу ограничена (у Temporal — 50К событий).
+// Поэтому между раздачами стол продолжается новым запуском (continue-as-new) с тем же состоянием
+const maxHistoryEvents = 10_000 // событий истории, после которых переход делается в ближайшей паузе
+
+// handsPerRun — раздач за один запуск; переменная, чтобы тесты доходили до перехода быстрее
+var handsPerRun = 100
+
+// continueAsNewDue — пора ли перейти в новый запуск. Посреди раздачи — никогда:
+// переход ждёт её конца
+func continueAsNewDue(ctx workflow.Context, state *RoomState, firstHand int) bool {
+	if handInProgress(state) || state.Terminated {
+		return false
+	}
+	info := workflow.GetInfo(ctx)
+	return info.GetContinueAsNewSuggested() ||
+		info.GetCurrentHistoryLength() >= maxHistoryEvents ||
+		state.HandNumber-firstHand >= handsPerRun
+}
+
+// carryOver — состояние для следующего запуска. История сыгранной раздачи уже сохранена
+// в базе, поэтому её действия и кадры повтора не переносятся. Колода и сиды сыгранной
+// раздачи тоже не нужны: сид уже раскрыт игрокам, следующая раздача тасует заново
+func carryOver(state *RoomState) *RoomState {
+	next := *state
+	next.Hand.Actions = nil
+	next.Hand.Replay = nil
+	next.Hand.MoveLog = nil
+	next.Hand.Deck = nil
+	next.Hand.Discards = nil
+	next.Hand.Fair = FairDeal{}
+	return &next
+}
+
+// restoreState готовит перенесённое состояние: пустые карты после JSON приходят как nil
+func restoreState(state *RoomState) *RoomState {
+	if state.Players == nil {
+		state.Players = make(map[string]bool)
+	}
+	if state.PlayerChips == nil {
+		state.PlayerChips = make(map[string]int64)
+	}
+	if state.TimeBanks == nil {
+		state.TimeBanks = make(map[string]time.Duration)
+	}
+	if state.ClientSeeds == nil {
+		state.ClientSeeds = make(map[string]string)
+	}
+	if state.PendingBuyIns == nil {
+		state.PendingBuyIns = make(map[string]EscrowInput)
+	}
+	if state.CashOutKeys == nil {
+		state.CashOutKeys = make(map[string]string)
+	}
+	return state
+}
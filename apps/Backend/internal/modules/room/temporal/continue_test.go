@@ -0,0 +1,116 @@
+package room_temporal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/variant"
+	"poker/packages/database"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mockTableActivities заменяет активности стола: сообщения уходят в никуда, книга отвечает успехом,
+// а расчёт ушедшего игрока не проходит — его ключ должен пережить переход
+func mockTableActivities(env *testsuite.TestWorkflowEnvironment) {
+	a := mock.Anything
+	env.OnActivity(SendMessageActivity, a, a, a, a).Return(nil)
+	env.OnActivity(SendCardToUserActivity, a, a, a, a).Return(nil)
+	env.OnActivity(SendGameStateActivity, a, a).Return(nil)
+	env.OnActivity(SendStatusToAllActivity, a, a).Return(nil)
+	env.OnActivity(SendWinnerPayloadActivity, a, a).Return(nil)
+	env.OnActivity(SendWinnerAnnouncementActivity, a, a).Return(nil)
+	env.OnActivity(DisconnectAllUsersActivity, a, a).Return(nil)
+	env.OnActivity(SaveGameHistoryActivity, a, a).Return(nil)
+	env.OnActivity(UpdateUserElo, a, a, a, a).Return(nil)
+	env.OnActivity(GetRoomActivity, a, a).Return(database.Room{}, nil)
+	env.OnActivity(BuyInActivity, a, a).Return("txn", nil)
+	env.OnActivity(SettleHandActivity, a, a).Return("txn", nil)
+	env.OnActivity(CashOutActivity, a, a).Return("", errors.New("ledger unavailable"))
+}
+
+// Сыграв handsPerRun раздач, стол уходит в новый запуск с местами, стеками, баттоном,
+// позицией ротации, банками времени и ключами незавершённых расчётов
+func TestRoomContinuesAsNewWithTableState(t *testing.T) {
+	defer func(n int) { handsPerRun = n }(handsPerRun)
+	handsPerRun = 1
+
+	carried := restoreState(&RoomState{
+		RoomID:      "room",
+		Players:     map[string]bool{"a": true, "b": true},
+		Seats:       []string{"a", "b", "c"},
+		PlayerChips: map[string]int64{"a": 1000, "b": 1000, "c": 300},
+		GameStarted: true,
+		Blinds:      Blinds{SmallBlind: 5, BigBlind: 10},
+		BuyIn:       BuyInLimits{Min: 200, Max: 1000},
+		Rotation: Rotation{Hands: 3, Games: []RotationGame{
+			{Game: variant.Holdem, Betting: variant.NoLimit},
+			{Game: variant.Omaha, Betting: variant.PotLimit},
+		}},
+		Mixed:       MixedState{Position: 1, HandsLeft: 2},
+		Game:        variant.Omaha,
+		Betting:     variant.PotLimit,
+		Clock:       ClockSettings{ActionTimeout: 30 * time.Second, TimeBank: 60 * time.Second},
+		TimeBanks:   map[string]time.Duration{"a": 20 * time.Second, "b": 35 * time.Second, "c": 50 * time.Second},
+		Dealer:      "a",
+		HandNumber:  10,
+		LedgerSeq:   7,
+		CashOutKeys: map[string]string{"c": "room/hand-10/7"},
+	})
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(StartRoomWorkflow)
+	mockTableActivities(env)
+
+	// Баттон переходит к b: в хедз-апе он на малом блайнде, ходит первым и сбрасывает
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("player-move", PlayerMoveSignal{UserID: "b", Action: "fold"})
+	}, nextHandDelay+time.Second)
+
+	env.ExecuteWorkflow(StartRoomWorkflow, "room", carried)
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("workflow did not complete")
+	}
+	var continued *workflow.ContinueAsNewError
+	if err := env.GetWorkflowError(); !errors.As(err, &continued) {
+		t.Fatalf("workflow error = %v, want continue-as-new", err)
+	}
+
+	var roomID string
+	var next RoomState
+	if err := converter.GetDefaultDataConverter().FromPayloads(continued.Input, &roomID, &next); err != nil {
+		t.Fatal(err)
+	}
+	if roomID != "room" || next.HandNumber != 11 {
+		t.Fatalf("continued room %q at hand %d, want room at hand 11", roomID, next.HandNumber)
+	}
+	if !reflect.DeepEqual(next.Seats, []string{"a", "b", "c"}) {
+		t.Fatalf("seats = %v", next.Seats)
+	}
+	if want := map[string]int64{"a": 1005, "b": 995, "c": 300}; !reflect.DeepEqual(next.PlayerChips, want) {
+		t.Fatalf("stacks = %v, want %v", next.PlayerChips, want)
+	}
+	if next.Dealer != "b" {
+		t.Fatalf("button = %s, want b", next.Dealer)
+	}
+	if next.Mixed.Position != 1 || next.Mixed.HandsLeft != 1 || next.Game != variant.Omaha {
+		t.Fatalf("rotation = %+v playing %s, want position 1 with 1 hand left of omaha", next.Mixed, next.Game)
+	}
+	want := map[string]time.Duration{"a": 30 * time.Second, "b": 45 * time.Second, "c": 50 * time.Second}
+	if !reflect.DeepEqual(next.TimeBanks, want) {
+		t.Fatalf("time banks = %v, want %v", next.TimeBanks, want)
+	}
+	if next.CashOutKeys["c"] != "room/hand-10/7" {
+		t.Fatalf("cash-out keys = %v, want c to keep room/hand-10/7", next.CashOutKeys)
+	}
+	if next.LedgerSeq <= 7 || next.PendingSettlement != nil {
+		t.Fatalf("ledger seq %d, pending settlement %+v", next.LedgerSeq, next.PendingSettlement)
+	}
+	if next.Hand.Actions != nil || next.Hand.Deck != nil || next.Hand.Fair.ServerSeed != "" {
+		t.Fatal("played hand's actions, deck or seed were carried over")
+	}
+}
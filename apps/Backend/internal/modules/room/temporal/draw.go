@@ -0,0 +1,114 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/cards"
+)
+
+// maxDraw — сколько карт можно сменить за один обмен
+const maxDraw = 5
+
+// ======================= DRAW =======================
+// DrawAction сбрасывает выбранные карты и добирает столько же из колоды.
+// Аргументы — сбрасываемые карты ("7s", "K♥", "10H"); без аргументов — игрок стоит на своих
+type DrawAction struct{}
+
+func (a DrawAction) Validate(state *RoomState, userID string, args []string) error {
+	if !state.Hand.Drawing {
+		return fmt.Errorf("cannot draw now")
+	}
+	if len(args) > maxDraw {
+		return fmt.Errorf("you can draw at most %d cards", maxDraw)
+	}
+	discards, err := parseDiscards(state.Hand.PlayerCards[userID], args)
+	if err != nil {
+		return err
+	}
+	if len(discards) > len(state.Hand.Deck) {
+		return fmt.Errorf("not enough cards in the deck: %d left", len(state.Hand.Deck))
+	}
+	return nil
+}
+
+func (a DrawAction) Execute(state *RoomState, userID string, args []string) {
+	discards, _ := parseDiscards(state.Hand.PlayerCards[userID], args)
+	drop := make(map[cards.Card]bool, len(discards))
+	for _, c := range discards {
+		drop[c] = true
+	}
+
+	kept := make([]cards.Card, 0, len(state.Hand.PlayerCards[userID]))
+	for _, c := range state.Hand.PlayerCards[userID] {
+		if !drop[c] {
+			kept = append(kept, c)
+		}
+	}
+	kept = append(kept, state.Hand.Deck.Draw(len(discards))...)
+
+	state.Hand.PlayerCards[userID] = kept
+	state.Hand.Discards = append(state.Hand.Discards, discards...)
+	state.Hand.HasDrawn[userID] = true
+}
+
+// parseDiscards разбирает сбрасываемые карты и проверяет, что они есть в руке
+func parseDiscards(hand []cards.Card, args []string) ([]cards.Card, error) {
+	inHand := make(map[cards.Card]bool, len(hand))
+	for _, c := range hand {
+		inHand[c] = true
+	}
+
+	discards := make([]cards.Card, 0, len(args))
+	seen := make(map[cards.Card]bool, len(args))
+	for _, arg := range args {
+		c, err := cards.Parse(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !inHand[c] {
+			return nil, fmt.Errorf("card %s is not in your hand", c)
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("card %s is listed twice", c)
+		}
+		seen[c] = true
+		discards = append(discards, c)
+	}
+	return discards, nil
+}
+
+// beginDraw открывает обмен: меняют карты все, кто не сбросил, начиная слева от баттона
+func beginDraw(ctx workflow.Context, state *RoomState) {
+	refillDeck(ctx, state)
+
+	state.Hand.Drawing = true
+	state.Hand.HasDrawn = make(map[string]bool)
+	state.Hand.CurrentPlayer = nextDrawer(state, state.Dealer)
+}
+
+// nextDrawer — следующий после from игрок, который ещё не менял карты
+func nextDrawer(state *RoomState, from string) string {
+	return nextPlayer(state.Hand.PlayerOrder, from, func(id string) bool {
+		return !state.Hand.PlayerFolded[id] && !state.Hand.HasDrawn[id]
+	})
+}
+
+// refillDeck замешивает сброс обратно в колоду, если её может не хватить на обмен
+func refillDeck(ctx workflow.Context, state *RoomState) {
+	active := 0
+	for _, id := range state.Hand.PlayerOrder {
+		if !state.Hand.PlayerFolded[id] {
+			active++
+		}
+	}
+	if len(state.Hand.Deck) >= active*maxDraw || len(state.Hand.Discards) == 0 {
+		return
+	}
+
+	shuffled := cards.Deck(append([]cards.Card(nil), state.Hand.Discards...))
+	reshuffle(state, shuffled)
+
+	state.Hand.Deck = append(state.Hand.Deck, shuffled...)
+	state.Hand.Discards = nil
+	sendToAllPlayers(ctx, state.RoomID, state.Players, "🔀 Discards reshuffled into the deck")
+}
@@ -0,0 +1,137 @@
+package room_temporal
+
+import (
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/variant"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDiscards(t *testing.T) {
+	hand := parseCards(t, "7s 5d 4h 3c Th")
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "stand pat", args: nil, want: ""},
+		{name: "any notation", args: []string{"10H", "7♠", "3C"}, want: "Th 7s 3c"},
+		{name: "card not in hand", args: []string{"2s"}, wantErr: "not in your hand"},
+		{name: "duplicate card", args: []string{"Th", "3c", "10h"}, wantErr: "listed twice"},
+		{name: "not a card", args: []string{"Zz"}, wantErr: "invalid card"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDiscards(hand, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseDiscards(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := parseCards(t, tt.want); len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Fatalf("parseDiscards(%v) = %v, want %v", tt.args, got, want)
+			}
+		})
+	}
+}
+
+// drawState — 2-7 на первом обмене: у a и b по пять карт, c сбросил; колода deck, сброс discards
+func drawState(t *testing.T, deck, discards string) *RoomState {
+	t.Helper()
+	state := &RoomState{Game: variant.TripleDraw, Hand: newHand(1, time.Time{}, "draw-1")}
+	state.Hand.PlayerOrder = []string{"a", "b", "c"}
+	state.Hand.PlayerCards["a"] = parseCards(t, "7s 5d 4h 3c Kh")
+	state.Hand.PlayerCards["b"] = parseCards(t, "8s 6d 4s 3d 2h")
+	state.Hand.PlayerFolded["c"] = true
+	state.Hand.Deck = cards.Deck(parseCards(t, deck))
+	state.Hand.Discards = parseCards(t, discards)
+	state.Hand.Drawing = true
+	state.Hand.HasDrawn = make(map[string]bool)
+	state.Hand.Fair.ServerSeed = "server-seed"
+	return state
+}
+
+func TestDrawActionReplacesDiscards(t *testing.T) {
+	state := drawState(t, "2c 6s 9d", "")
+	action := DrawAction{}
+	if err := action.Validate(state, "a", []string{"Kh", "7s"}); err != nil {
+		t.Fatal(err)
+	}
+	action.Execute(state, "a", []string{"Kh", "7s"})
+
+	if got, want := state.Hand.PlayerCards["a"], parseCards(t, "5d 4h 3c 2c 6s"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("hand after draw = %v, want %v", got, want)
+	}
+	if got, want := state.Hand.Discards, parseCards(t, "Kh 7s"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("discards = %v, want %v", got, want)
+	}
+	if len(state.Hand.Deck) != 1 || !state.Hand.HasDrawn["a"] {
+		t.Fatalf("deck %v, drawn %v", state.Hand.Deck, state.Hand.HasDrawn)
+	}
+
+	// В колоде одна карта — на обмен двух её не хватит
+	if err := action.Validate(state, "b", []string{"8s", "6d"}); err == nil {
+		t.Fatal("draw of 2 cards from a deck of 1 accepted")
+	}
+}
+
+// refillWorkflow — refillDeck сообщает о замесе активностью, поэтому работает в воркфлоу
+func refillWorkflow(ctx workflow.Context, state RoomState) (RoomState, error) {
+	refillDeck(ctx, &state)
+	return state, nil
+}
+
+func runRefill(t *testing.T, state *RoomState) RoomState {
+	t.Helper()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(refillWorkflow)
+	env.ExecuteWorkflow(refillWorkflow, *state)
+	var result RoomState
+	if err := env.GetWorkflowResult(&result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func sortedCards(c []cards.Card) []cards.Card {
+	c = append([]cards.Card(nil), c...)
+	sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+	return c
+}
+
+func TestRefillDeck(t *testing.T) {
+	// Двум игрокам нужно до 10 карт, в колоде 3: сброс замешивается под низ колоды
+	state := drawState(t, "2c 6s 9d", "Qd Jc Ts 9h 8c Ah Kd")
+	result := runRefill(t, state)
+	if result.Hand.Discards != nil || result.Hand.Fair.Reshuffles != 1 {
+		t.Fatalf("discards %v, reshuffles %d, want none and 1", result.Hand.Discards, result.Hand.Fair.Reshuffles)
+	}
+	if len(result.Hand.Deck) != 10 || !reflect.DeepEqual([]cards.Card(result.Hand.Deck[:3]), parseCards(t, "2c 6s 9d")) {
+		t.Fatalf("deck after reshuffle = %v", result.Hand.Deck)
+	}
+	if got, want := sortedCards(result.Hand.Deck[3:]), sortedCards(state.Hand.Discards); !reflect.DeepEqual(got, want) {
+		t.Fatalf("reshuffled cards = %v, want the discards %v", got, want)
+	}
+
+	// Тот же сид — тот же порядок: замес проверяется так же, как исходное тасование
+	if again := runRefill(t, drawState(t, "2c 6s 9d", "Qd Jc Ts 9h 8c Ah Kd")); !reflect.DeepEqual(again.Hand.Deck, result.Hand.Deck) {
+		t.Fatalf("reshuffle is not deterministic: %v and %v", result.Hand.Deck, again.Hand.Deck)
+	}
+
+	// Колоды хватает — сброс не трогается
+	state = drawState(t, "2c 6s 9d Ah Ad As Ac Kd Ks Kc", "Qd Jc")
+	if result := runRefill(t, state); len(result.Hand.Deck) != 10 || len(result.Hand.Discards) != 2 {
+		t.Fatalf("full deck reshuffled: deck %v, discards %v", result.Hand.Deck, result.Hand.Discards)
+	}
+}
@@ -0,0 +1,97 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/fairness"
+	"poker/internal/modules/room/variant"
+)
+
+// FairDeal — всё, что нужно игроку для проверки тасования раздачи.
+// Хэш серверного сида публикуется заранее, сам сид — только после раздачи
+type FairDeal struct {
+	Commitment  string
+	ServerSeed  string
+	ClientSeeds map[string]string // сиды участников раздачи на момент сдачи
+	Revealed    bool
+	Reshuffles  int // сколько раз сброс замешивался обратно в колоду
+}
+
+// newServerSeed — секретный сид из криптостойкого генератора; в истории воркфлоу он записывается один раз
+func newServerSeed(ctx workflow.Context) string {
+	var seed string
+	_ = workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
+		return fairness.NewServerSeed()
+	}).Get(&seed)
+	return seed
+}
+
+// prepareServerSeed заготавливает сид следующей раздачи, чтобы его хэш был виден до сдачи
+func prepareServerSeed(ctx workflow.Context, state *RoomState) {
+	state.NextServerSeed = newServerSeed(ctx)
+	state.NextCommitment = fairness.Commit(state.NextServerSeed)
+}
+
+// shuffleDeck тасует колоду раздачи по заранее объявленному серверному сиду и сидам игроков
+func shuffleDeck(ctx workflow.Context, state *RoomState) cards.Deck {
+	if state.NextServerSeed == "" {
+		prepareServerSeed(ctx, state)
+	}
+
+	seeds := make(map[string]string)
+	for _, id := range state.Hand.PlayerOrder {
+		if seed, ok := state.ClientSeeds[id]; ok {
+			seeds[id] = seed
+		}
+	}
+	state.Hand.Fair = FairDeal{
+		Commitment:  state.NextCommitment,
+		ServerSeed:  state.NextServerSeed,
+		ClientSeeds: seeds,
+	}
+	deck := fairness.Deal(state.Hand.Fair.ServerSeed, seeds, variant.Get(state.Game).MinRank)
+
+	// Хэш следующей раздачи публикуется сразу: игроки успеют сменить свои сиды
+	prepareServerSeed(ctx, state)
+	return deck
+}
+
+// reshuffle замешивает карты по сиду раздачи, так что и повторное тасование можно проверить
+func reshuffle(state *RoomState, deck cards.Deck) {
+	state.Hand.Fair.Reshuffles++
+	seed := fairness.Seed(state.Hand.Fair.ServerSeed, state.Hand.Fair.ClientSeeds)
+	fairness.Shuffle(deck, fairness.Derive(seed, fmt.Sprintf("reshuffle-%d", state.Hand.Fair.Reshuffles)))
+}
+
+// setClientSeed — сид игрока; он пойдёт в тасование со следующей раздачи
+func setClientSeed(state *RoomState, userID, seed string) error {
+	if !state.Players[userID] {
+		return fmt.Errorf("player %s not in room", userID)
+	}
+	if err := fairness.CheckClientSeed(seed); err != nil {
+		return err
+	}
+	state.ClientSeeds[userID] = seed
+	return nil
+}
+
+// fairnessPayload — хэши, сиды и алгоритм для проверки. Серверный сид — только у сыгранной раздачи
+func fairnessPayload(state RoomState) map[string]interface{} {
+	payload := map[string]interface{}{
+		"algorithm":      fairness.Algorithm,
+		"nextCommitment": state.NextCommitment,
+	}
+	fair := state.Hand.Fair
+	if fair.Commitment == "" {
+		return payload
+	}
+	payload["hand"] = state.Hand.Number
+	payload["commitment"] = fair.Commitment
+	payload["clientSeeds"] = fair.ClientSeeds
+	if fair.Revealed {
+		payload["serverSeed"] = fair.ServerSeed
+		payload["reshuffles"] = fair.Reshuffles
+	}
+	return payload
+}
@@ -0,0 +1,60 @@
+package room_temporal
+
+import (
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/fairness"
+	"poker/internal/modules/room/variant"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func shuffleWorkflow(ctx workflow.Context, state RoomState) (RoomState, error) {
+	state.Hand.Deck = shuffleDeck(ctx, &state)
+	return state, nil
+}
+
+// Колоду раздачи можно восстановить по раскрытому сиду: он совпадает с объявленным хэшем,
+// а в тасование идут сиды только участников раздачи
+func TestShuffleDeckIsVerifiable(t *testing.T) {
+	const serverSeed = "server-seed-of-hand-1"
+	state := RoomState{
+		Game:           variant.Holdem,
+		NextServerSeed: serverSeed,
+		NextCommitment: fairness.Commit(serverSeed),
+		ClientSeeds:    map[string]string{"a": "lucky", "spectator": "ignored"},
+		Hand:           newHand(1, time.Time{}, "preflop"),
+	}
+	state.Hand.PlayerOrder = []string{"a", "b"}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(shuffleWorkflow)
+	env.ExecuteWorkflow(shuffleWorkflow, state)
+	var result RoomState
+	if err := env.GetWorkflowResult(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	fair := result.Hand.Fair
+	if fair.ServerSeed != serverSeed || fair.Commitment != state.NextCommitment {
+		t.Fatalf("hand used seed %q with commitment %q, want the announced ones", fair.ServerSeed, fair.Commitment)
+	}
+	if want := map[string]string{"a": "lucky"}; !reflect.DeepEqual(fair.ClientSeeds, want) {
+		t.Fatalf("client seeds = %v, want %v", fair.ClientSeeds, want)
+	}
+	deck, err := fairness.Verify(fair.ServerSeed, fair.Commitment, fair.ClientSeeds, cards.Two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deck, result.Hand.Deck) {
+		t.Fatalf("verified deck differs from the dealt one:\n%v\n%v", deck, result.Hand.Deck)
+	}
+
+	// Хэш следующей раздачи объявлен сразу и относится к новому сиду
+	if result.NextServerSeed == serverSeed || result.NextCommitment != fairness.Commit(result.NextServerSeed) {
+		t.Fatalf("next seed %q with commitment %q", result.NextServerSeed, result.NextCommitment)
+	}
+}
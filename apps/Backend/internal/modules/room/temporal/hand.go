@@ -0,0 +1,331 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/variant"
+	"strings"
+	"time"
+)
+
+// nextHandDelay — пауза между раздачами
+const nextHandDelay = 5 * time.Second
+
+// HandState — состояние одной раздачи. Сбрасывается перед каждой новой раздачей
+type HandState struct {
+	Number         int
+	StartTime      time.Time
+	PlayerOrder    []string // участники раздачи по кругу
+	StartingStacks map[string]int64
+	PlayerFolded   map[string]bool
+	PlayerAllIn    map[string]bool
+	CurrentPlayer  string
+	CurrentBet     int64
+	LastRaise      int64
+	Raises         int              // ставок и повышений на текущей улице
+	Pot            int64            // все фишки в банке, включая побочные банки
+	Contributions  map[string]int64 // сколько каждый вложил в банк за раздачу
+	Pots           []SidePot        // разбивка банка на вскрытии
+	Rake           int64            // рейк раздачи, уже снят с банков
+	Results        []PotResult
+	Winners        []WinnerShare // итоговые выигрыши по всем банкам
+	MoveLog        []string
+	Actions        []HandAction            // действия для истории раздачи
+	Replay         []ReplayFrame           // кадры пошагового повтора
+	EndStreet      string                  // улица, на которой раздача закончилась, или showdown
+	PlayerCards    map[string][]cards.Card // все карты игрока, закрытые и открытые
+	UpCards        map[string][]cards.Card // открытые карты (стад)
+	Discards       []cards.Card            // сброс (дро-игры)
+	Drawing        bool                    // идёт обмен карт, а не торговля
+	HasDrawn       map[string]bool
+	Deck           cards.Deck
+	BoardCards     []cards.Card
+	RoundStage     string
+	HasActed       map[string]bool
+	PlayerBets     map[string]int64
+
+	SmallBlindPlayer string
+	BigBlindPlayer   string
+	BringInPlayer    string // стад: кто поставил бринг-ин
+
+	Clock TurnClock // часы текущего хода
+	Fair  FairDeal  // сиды тасования
+}
+
+func newHand(number int, startTime time.Time, firstStreet string) HandState {
+	return HandState{
+		Number:         number,
+		StartTime:      startTime,
+		PlayerOrder:    make([]string, 0),
+		StartingStacks: make(map[string]int64),
+		PlayerFolded:   make(map[string]bool),
+		PlayerAllIn:    make(map[string]bool),
+		Contributions:  make(map[string]int64),
+		MoveLog:        []string{},
+		PlayerCards:    make(map[string][]cards.Card),
+		UpCards:        make(map[string][]cards.Card),
+		RoundStage:     firstStreet,
+		HasActed:       make(map[string]bool),
+		PlayerBets:     make(map[string]int64),
+	}
+}
+
+// handInProgress — раздача сдана и ещё не завершена
+func handInProgress(state *RoomState) bool {
+	return state.Hand.RoundStage != "" && state.Hand.RoundStage != "ended"
+}
+
+// seatPlayer сажает игрока за стол; в раздаче он участвует со следующей раздачи
+func seatPlayer(state *RoomState, userID string) {
+	for _, id := range state.Seats {
+		if id == userID {
+			return
+		}
+	}
+	state.Seats = append(state.Seats, userID)
+}
+
+// releaseSeats освобождает места игроков, покинувших комнату; их стеки возвращаются в кошельки.
+// Место с неудавшимся расчётом остаётся занятым до следующей попытки
+func releaseSeats(ctx workflow.Context, state *RoomState) {
+	seats := state.Seats[:0]
+	for _, id := range state.Seats {
+		_, pending := state.CashOutKeys[id]
+		if state.Players[id] && !pending {
+			seats = append(seats, id)
+			continue
+		}
+		if !cashOut(ctx, state, id) {
+			seats = append(seats, id)
+			continue
+		}
+		delete(state.ReadyPlayers, id)
+		delete(state.TimeBanks, id)
+	}
+	state.Seats = seats
+}
+
+// handleLeave сбрасывает карты ушедшего игрока, чтобы раздача не остановилась.
+// Между раздачами стек сразу возвращается в кошелёк
+func handleLeave(ctx workflow.Context, state *RoomState, userID string) {
+	if !handInProgress(state) {
+		cashOut(ctx, state, userID)
+		return
+	}
+	if _, inHand := state.Hand.PlayerFolded[userID]; !inHand || state.Hand.PlayerFolded[userID] {
+		return
+	}
+
+	state.Hand.PlayerFolded[userID] = true
+	state.Hand.MoveLog = append(state.Hand.MoveLog, fmt.Sprintf("%s: fold (left the table)", userID))
+	recordAction(state, HandAction{UserID: userID, Action: "fold", At: workflow.Now(ctx)})
+
+	if state.Hand.CurrentPlayer == userID || IsBettingRoundOver(state) {
+		NextTurn(ctx, state)
+	}
+	if handInProgress(state) {
+		recordFrame(ctx, state)
+	}
+}
+
+// applyMove выполняет проверенное действие игрока и передаёт ход дальше
+func applyMove(ctx workflow.Context, state *RoomState, userID, action string, args []string) {
+	entry := strings.TrimSpace(fmt.Sprintf("%s: %s %s", userID, action, strings.Join(args, " ")))
+	if action == "draw" {
+		// Сброшенные карты остаются закрытыми — остальным виден только их счёт
+		entry = fmt.Sprintf("%s: draw %d", userID, len(args))
+	}
+	state.Hand.MoveLog = append(state.Hand.MoveLog, entry)
+	sendToAllPlayers(ctx, state.RoomID, state.Players, entry)
+
+	handler := ActionRegistry[action]
+	prevBet, prevRaise := state.Hand.CurrentBet, state.Hand.LastRaise
+	prevContribution := state.Hand.Contributions[userID]
+	handler.Execute(state, userID, args)
+
+	move := HandAction{UserID: userID, Action: action, Amount: state.Hand.Contributions[userID] - prevContribution, At: workflow.Now(ctx)}
+	if action == "draw" {
+		move.Discarded = len(args)
+	}
+	recordAction(state, move)
+
+	// После полного повышения остальные должны ответить на новую ставку и снова могут повышать.
+	// Неполный олл-ин торговлю не открывает: кто уже ходил, только уравнивает или сбрасывает
+	if raise := state.Hand.CurrentBet - prevBet; raise > 0 && (action != "allin" || raise >= prevRaise) {
+		for id := range state.Hand.HasActed {
+			state.Hand.HasActed[id] = false
+		}
+	}
+	state.Hand.HasActed[userID] = true
+
+	NextTurn(ctx, state)
+	// Последний кадр раздачи пишет finishHand
+	if handInProgress(state) {
+		recordFrame(ctx, state)
+	}
+}
+
+// startHand сдаёт следующую раздачу. Если играть некому — сессия ставится на паузу
+func startHand(ctx workflow.Context, state *RoomState) {
+	logger := workflow.GetLogger(ctx)
+
+	// Стеки прошлой раздачи должны совпасть с книгой до того, как пойдут новые ставки
+	if !settledOrHalt(ctx, state) {
+		return
+	}
+
+	// На смешанном столе игра выбирается до того, как сдаются карты
+	rotateGame(ctx, state)
+
+	rules := variant.Get(state.Game)
+	hand := newHand(state.HandNumber+1, workflow.Now(ctx), rules.Streets[0].Name)
+	for _, id := range state.Seats {
+		if !state.Players[id] {
+			continue
+		}
+
+		// Стек, который ещё возвращается в кошелёк, в игре не участвует
+		if _, pending := state.CashOutKeys[id]; pending {
+			continue
+		}
+
+		// Играют только те, кто принёс фишки за стол
+		if state.PlayerChips[id] <= 0 {
+			sendToPlayer(ctx, state.RoomID, id, fmt.Sprintf("💰 Buy in to play: %d to %d chips", state.BuyIn.Min, state.BuyIn.Max))
+			continue
+		}
+
+		refillTimeBank(state, id)
+		hand.PlayerOrder = append(hand.PlayerOrder, id)
+		hand.StartingStacks[id] = state.PlayerChips[id]
+		hand.PlayerFolded[id] = false
+		hand.PlayerAllIn[id] = false
+	}
+
+	if len(hand.PlayerOrder) < 2 {
+		logger.Info("⏸️ Not enough players with chips — session paused")
+		state.GameStarted = false
+		releaseSeats(ctx, state)
+		sendToAllPlayers(ctx, state.RoomID, state.Players, "⏸️ Waiting for at least 2 players with chips")
+		return
+	}
+
+	state.HandNumber = hand.Number
+	state.Hand = hand
+
+	// Баттон двигается по местам до того, как освободятся места ушедших
+	moveButton(state)
+	releaseSeats(ctx, state)
+
+	postBlinds(state)
+
+	logger.Info("🎮 Hand started",
+		zap.Int("hand", state.HandNumber),
+		zap.String("dealer", state.Dealer),
+		zap.String("smallBlind", state.Hand.SmallBlindPlayer),
+		zap.String("bigBlind", state.Hand.BigBlindPlayer),
+	)
+	sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🎮 Hand #%d started!", state.HandNumber))
+	if !rules.Stud {
+		sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🔘 Dealer: %s, SB: %s (%d), BB: %s (%d)",
+			state.Dealer, state.Hand.SmallBlindPlayer, state.Blinds.SmallBlind, state.Hand.BigBlindPlayer, state.Blinds.BigBlind))
+	}
+
+	futures := dealCards(ctx, state, state.RoomID)
+	for _, f := range futures {
+		if err := f.Get(ctx, nil); err != nil {
+			logger.Error("Failed to deal cards", zap.Error(err))
+		}
+	}
+
+	// В стаде бринг-ин ставится по открытым картам, поэтому уже после раздачи
+	if rules.Stud {
+		if bringIn, amount := postBringIn(state); bringIn != "" {
+			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🃏 %s brings in for %d", bringIn, amount))
+		}
+	}
+
+	recordFrame(ctx, state)
+
+	// Все в олл-ине уже на блайндах — сразу докладываем борд
+	if state.Hand.CurrentPlayer == "" || IsBettingRoundOver(state) {
+		NextTurn(ctx, state)
+		return
+	}
+
+	sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🕓 First turn: %s", state.Hand.CurrentPlayer))
+	sendToPlayer(ctx, state.RoomID, state.Hand.CurrentPlayer, "🟢 Your turn")
+}
+
+// finishHand выплачивает банки победителям и закрывает раздачу.
+// Следующую раздачу воркфлоу сдаст после nextHandDelay
+func finishHand(ctx workflow.Context, state *RoomState, results []PotResult) {
+	logger := workflow.GetLogger(ctx)
+
+	state.Hand.Results = results
+	state.Hand.EndStreet = state.Hand.RoundStage
+	state.Hand.RoundStage = "ended"
+	state.Hand.CurrentPlayer = ""
+
+	state.Hand.Winners = totalPayouts(results)
+
+	// Раздача сыграна — серверный сид можно раскрыть
+	state.Hand.Fair.Revealed = true
+	sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🔓 Hand #%d server seed: %s", state.Hand.Number, state.Hand.Fair.ServerSeed))
+
+	// Победитель раздачи — тот, кто забрал (или разделил) основной банк
+	winner := results[0].Winners[0].UserID
+	announceWinner(ctx, state, state.Hand.Winners, logger)
+	if state.Hand.Rake > 0 {
+		sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🏠 Rake: %d", state.Hand.Rake))
+	}
+
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Second,
+	}
+	actCtx := workflow.WithActivityOptions(ctx, ao)
+
+	_ = workflow.ExecuteActivity(actCtx, SendWinnerPayloadActivity, WinnerPayloadInput{
+		RoomID:      state.RoomID,
+		Players:     state.Players,
+		View:        winnerView(state, winner),
+		PlayerCards: state.Hand.PlayerCards,
+	}).Get(actCtx, nil)
+
+	// Выигрыш остаётся в стеке на столе; в кошелёк он вернётся при расчёте
+	for _, w := range state.Hand.Winners {
+		state.PlayerChips[w.UserID] += w.Amount
+	}
+	settleHand(ctx, state)
+	recordFrame(ctx, state)
+
+	// 🔄 Обновление ELO
+	var eloUpdates []workflow.Future
+	mainWinners := make(map[string]bool)
+	for _, w := range results[0].Winners {
+		mainWinners[w.UserID] = true
+		eloUpdates = append(eloUpdates, workflow.ExecuteActivity(actCtx, UpdateUserElo, w.UserID, int64(10), true)) // победителю +10
+	}
+	for _, id := range state.Hand.PlayerOrder {
+		if !mainWinners[id] && !state.Hand.PlayerFolded[id] {
+			eloUpdates = append(eloUpdates, workflow.ExecuteActivity(actCtx, UpdateUserElo, id, int64(-10), false)) // проигравшим -10
+		}
+	}
+
+	saveCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: 10 * time.Second})
+	if err := workflow.ExecuteActivity(saveCtx, SaveGameHistoryActivity, handRecord(state)).Get(saveCtx, nil); err != nil {
+		logger.Error("❌ Failed to save history", "err", err)
+	}
+
+	// После раздачи стол может уйти в новый запуск (continue-as-new), а незавершённые
+	// активности старого запуска при этом теряются — поэтому ELO дожидаемся здесь
+	for _, f := range eloUpdates {
+		if err := f.Get(actCtx, nil); err != nil {
+			logger.Error("❌ Failed to update ELO", "err", err)
+		}
+	}
+
+	logger.Info("🏁 Hand finished", zap.Int("hand", state.Hand.Number), zap.String("winner", winner))
+}
@@ -0,0 +1,166 @@
+package room_temporal
+
+import (
+	"encoding/json"
+	"fmt"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/variant"
+	"poker/packages/database"
+	"strings"
+	"time"
+)
+
+// HandAction — действие раздачи для истории: ставки, блайнды и обмены карт
+type HandAction struct {
+	UserID    string
+	Street    string
+	Action    string
+	Amount    int64 // фишек добавлено в банк
+	Total     int64 // ставка игрока на улице после действия
+	AllIn     bool
+	Discarded int
+	At        time.Time
+}
+
+// recordAction дописывает действие в историю раздачи; улица и ставка берутся из состояния
+func recordAction(state *RoomState, a HandAction) {
+	a.Street = state.Hand.RoundStage
+	a.Total = state.Hand.PlayerBets[a.UserID]
+	a.AllIn = state.Hand.PlayerAllIn[a.UserID]
+	state.Hand.Actions = append(state.Hand.Actions, a)
+}
+
+// handSessionID — ID сохранённой раздачи; один и тот же при повторном сохранении.
+// Без "#" и других символов, которые пришлось бы экранировать в URL
+func handSessionID(roomID string, hand int) string {
+	return fmt.Sprintf("%s-%d", roomID, hand)
+}
+
+// HandRecord — вход активности сохранения: раздача и настройки стола, которые попадают в историю.
+// Колода и сиды тасования в него не входят
+type HandRecord struct {
+	RoomID  string
+	Game    variant.GameType
+	Betting variant.BettingStructure
+	Blinds  Blinds
+	Dealer  string
+	Seats   []string
+	Hand    HandState
+}
+
+func handRecord(state *RoomState) HandRecord {
+	hand := state.Hand
+	hand.Deck = nil
+	hand.Discards = nil
+	hand.Fair = FairDeal{}
+	hand.MoveLog = nil
+	return HandRecord{
+		RoomID:  state.RoomID,
+		Game:    state.Game,
+		Betting: state.Betting,
+		Blinds:  state.Blinds,
+		Dealer:  state.Dealer,
+		Seats:   state.Seats,
+		Hand:    hand,
+	}
+}
+
+// handHistory собирает завершённую раздачу в строки таблиц истории
+func handHistory(state HandRecord) (database.GameSession, error) {
+	hand := state.Hand
+	id := handSessionID(state.RoomID, hand.Number)
+
+	results, err := json.Marshal(hand.Results)
+	if err != nil {
+		return database.GameSession{}, fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	blinds := fmt.Sprintf("%d/%d", state.Blinds.SmallBlind, state.Blinds.BigBlind)
+	if state.Blinds.Ante > 0 {
+		blinds += fmt.Sprintf("/%d", state.Blinds.Ante)
+	}
+
+	session := database.GameSession{
+		ID:               id,
+		RoomID:           state.RoomID,
+		Round:            hand.Number,
+		Pot:              hand.Pot,
+		Rake:             hand.Rake,
+		Status:           hand.EndStreet,
+		GameType:         string(state.Game),
+		BettingStructure: string(state.Betting),
+		Blinds:           blinds,
+		Dealer:           state.Dealer,
+		Board:            shortCards(hand.BoardCards),
+		Results:          string(results),
+		StartedAt:        hand.StartTime.Unix(),
+		CreatedAt:        time.Now().Unix(),
+	}
+
+	won := make(map[string]int64)
+	described := make(map[string]string)
+	for _, w := range hand.Winners {
+		won[w.UserID] += w.Amount
+		described[w.UserID] = w.Hand
+	}
+	for _, userID := range hand.PlayerOrder {
+		session.Players = append(session.Players, database.GamePlayer{
+			ID:         id + "/" + userID,
+			GameID:     id,
+			UserID:     userID,
+			SeatNumber: seatNumber(state.Seats, userID),
+			Chips:      hand.StartingStacks[userID],
+			IsFolded:   hand.PlayerFolded[userID],
+			IsAllIn:    hand.PlayerAllIn[userID],
+			HoleCards:  shortCards(hand.PlayerCards[userID]),
+			UpCards:    shortCards(hand.UpCards[userID]),
+			Won:        won[userID],
+			Hand:       described[userID],
+		})
+	}
+
+	streets := make(map[string]int)
+	for i, s := range variant.Get(state.Game).Streets {
+		streets[s.Name] = i
+	}
+	for i, a := range hand.Actions {
+		session.Moves = append(session.Moves, database.GameMove{
+			ID:          fmt.Sprintf("%s/%d", id, i+1),
+			GameID:      id,
+			PlayerID:    a.UserID,
+			Seq:         i + 1,
+			Street:      a.Street,
+			Action:      a.Action,
+			Amount:      a.Amount,
+			Total:       a.Total,
+			IsAllIn:     a.AllIn,
+			Discarded:   a.Discarded,
+			RoundNumber: streets[a.Street],
+			CreatedAt:   a.At.Unix(),
+		})
+	}
+
+	if session.Snapshots, err = replaySnapshots(id, hand.Replay); err != nil {
+		return database.GameSession{}, err
+	}
+	return session, nil
+}
+
+// seatNumber — место игрока за столом, с единицы
+func seatNumber(seats []string, userID string) int {
+	for i, id := range seats {
+		if id == userID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// shortCards — карты в короткой записи через пробел: "Ts 9h"
+func shortCards(cs []cards.Card) string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.Short()
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,160 @@
+package room_temporal
+
+// SidePot — банк и игроки, которые могут его выиграть. Первый банк — основной
+type SidePot struct {
+	Amount   int64    `json:"amount"`
+	Eligible []string `json:"eligible"`
+	Rake     int64    `json:"rake,omitempty"` // снято в пользу заведения до дележа
+}
+
+// PotResult — как разделён банк на вскрытии
+type PotResult struct {
+	Amount  int64         `json:"amount"`
+	Rake    int64         `json:"rake,omitempty"`
+	Winners []WinnerShare `json:"winners"`
+	Scoop   bool          `json:"scoop,omitempty"` // хай-лоу: весь банк у одного игрока
+}
+
+// WinnerShare — доля победителя в банке
+type WinnerShare struct {
+	UserID string `json:"userId"`
+	Amount int64  `json:"amount"`
+	Hand   string `json:"hand,omitempty"`
+	Side   string `json:"side,omitempty"` // хай-лоу: "hi" или "lo"
+}
+
+// BuildPots делит вклады игроков на основной и побочные банки по уровням олл-инов.
+// Фишки сбросивших игроков остаются в банках, но выиграть их те не могут.
+func BuildPots(order []string, contributions map[string]int64, folded map[string]bool) []SidePot {
+	remaining := make(map[string]int64, len(contributions))
+	for id, amount := range contributions {
+		remaining[id] = amount
+	}
+
+	var pots []SidePot
+	for {
+		// Уровень банка — наименьший оставшийся вклад среди игроков в раздаче
+		var level int64
+		for _, id := range order {
+			if folded[id] || remaining[id] <= 0 {
+				continue
+			}
+			if level == 0 || remaining[id] < level {
+				level = remaining[id]
+			}
+		}
+		if level == 0 {
+			break
+		}
+
+		pot := SidePot{}
+		for _, id := range order {
+			take := remaining[id]
+			if take > level {
+				take = level
+			}
+			if take <= 0 {
+				continue
+			}
+			pot.Amount += take
+			remaining[id] -= take
+			if !folded[id] {
+				pot.Eligible = append(pot.Eligible, id)
+			}
+		}
+		pots = appendPot(pots, pot)
+	}
+
+	// Фишки сбросивших сверх последнего уровня достаются последнему банку
+	var leftover int64
+	for _, id := range order {
+		leftover += remaining[id]
+	}
+	if leftover > 0 && len(pots) > 0 {
+		pots[len(pots)-1].Amount += leftover
+	}
+	return pots
+}
+
+// appendPot сливает банки с одинаковым составом претендентов
+func appendPot(pots []SidePot, pot SidePot) []SidePot {
+	if len(pots) > 0 && sameEligible(pots[len(pots)-1].Eligible, pot.Eligible) {
+		pots[len(pots)-1].Amount += pot.Amount
+		return pots
+	}
+	return append(pots, pot)
+}
+
+func sameEligible(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// currentPots — разбивка банка текущей раздачи
+func currentPots(state *RoomState) []SidePot {
+	return BuildPots(state.Hand.PlayerOrder, state.Hand.Contributions, state.Hand.PlayerFolded)
+}
+
+// SplitPot делит банк поровну между победителями. Лишние фишки раздаются по одной,
+// начиная с первого победителя слева от баттона
+func SplitPot(amount int64, winners []string, order []string, dealer string) []WinnerShare {
+	if len(winners) == 0 {
+		return nil
+	}
+
+	isWinner := make(map[string]bool, len(winners))
+	for _, id := range winners {
+		isWinner[id] = true
+	}
+	ordered := make([]string, 0, len(winners))
+	id := dealer
+	for range order {
+		id = nextPlayer(order, id, anyPlayer)
+		if isWinner[id] {
+			ordered = append(ordered, id)
+		}
+	}
+	if len(ordered) == 0 {
+		return nil
+	}
+
+	share := amount / int64(len(ordered))
+	odd := amount % int64(len(ordered))
+
+	shares := make([]WinnerShare, len(ordered))
+	for i, id := range ordered {
+		shares[i] = WinnerShare{UserID: id, Amount: share}
+		if int64(i) < odd {
+			shares[i].Amount++
+		}
+	}
+	return shares
+}
+
+// totalPayouts суммирует выигрыши каждого игрока по всем банкам
+func totalPayouts(results []PotResult) []WinnerShare {
+	var total []WinnerShare
+	index := make(map[string]int)
+	for _, r := range results {
+		for _, w := range r.Winners {
+			i, ok := index[w.UserID]
+			if !ok {
+				index[w.UserID] = len(total)
+				total = append(total, w)
+				continue
+			}
+			total[i].Amount += w.Amount
+			if total[i].Side != w.Side {
+				total[i].Side = "" // забрал и хай, и лоу
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,251 @@
+package room_temporal
+
+import (
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/variant"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildPots(t *testing.T) {
+	tests := []struct {
+		name          string
+		order         []string
+		contributions map[string]int64
+		folded        map[string]bool
+		want          []SidePot
+	}{
+		{
+			name:          "no all-in",
+			order:         []string{"a", "b", "c"},
+			contributions: map[string]int64{"a": 200, "b": 200, "c": 200},
+			want:          []SidePot{{Amount: 600, Eligible: []string{"a", "b", "c"}}},
+		},
+		{
+			name:          "all-ins at different stacks",
+			order:         []string{"a", "b", "c", "d"},
+			contributions: map[string]int64{"a": 100, "b": 300, "c": 500, "d": 500},
+			want: []SidePot{
+				{Amount: 400, Eligible: []string{"a", "b", "c", "d"}},
+				{Amount: 600, Eligible: []string{"b", "c", "d"}},
+				{Amount: 400, Eligible: []string{"c", "d"}},
+			},
+		},
+		{
+			// Сбросивший e оставил 200: по 100 в двух банках, выиграть их он не может
+			name:          "folded player contributed",
+			order:         []string{"a", "b", "c", "d", "e"},
+			contributions: map[string]int64{"a": 100, "b": 300, "c": 500, "d": 500, "e": 200},
+			folded:        map[string]bool{"e": true},
+			want: []SidePot{
+				{Amount: 500, Eligible: []string{"a", "b", "c", "d"}},
+				{Amount: 700, Eligible: []string{"b", "c", "d"}},
+				{Amount: 400, Eligible: []string{"c", "d"}},
+			},
+		},
+		{
+			// Сбросивший вложил больше всех оставшихся: излишек уходит в последний банк
+			name:          "folded chips above the last level",
+			order:         []string{"a", "b", "c"},
+			contributions: map[string]int64{"a": 100, "b": 100, "c": 300},
+			folded:        map[string]bool{"c": true},
+			want:          []SidePot{{Amount: 500, Eligible: []string{"a", "b"}}},
+		},
+		{
+			name:          "everyone folded but one",
+			order:         []string{"a", "b", "c"},
+			contributions: map[string]int64{"a": 50, "b": 100, "c": 20},
+			folded:        map[string]bool{"a": true, "c": true},
+			want:          []SidePot{{Amount: 170, Eligible: []string{"b"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildPots(tt.order, tt.contributions, tt.folded)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BuildPots = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendPotMergesSameEligible(t *testing.T) {
+	pots := []SidePot{
+		{Amount: 300, Eligible: []string{"a", "b", "c"}},
+		{Amount: 200, Eligible: []string{"b", "c"}},
+	}
+	pots = appendPot(pots, SidePot{Amount: 100, Eligible: []string{"b", "c"}})
+	want := []SidePot{
+		{Amount: 300, Eligible: []string{"a", "b", "c"}},
+		{Amount: 300, Eligible: []string{"b", "c"}},
+	}
+	if !reflect.DeepEqual(pots, want) {
+		t.Fatalf("same eligible set: %+v, want %+v", pots, want)
+	}
+
+	// Тот же состав, но не подряд или в другом порядке — отдельный банк
+	pots = appendPot(pots, SidePot{Amount: 50, Eligible: []string{"c", "b"}})
+	if len(pots) != 3 || pots[2].Amount != 50 {
+		t.Fatalf("different eligible order merged: %+v", pots)
+	}
+}
+
+func TestSplitPotOddChips(t *testing.T) {
+	order := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name    string
+		amount  int64
+		winners []string
+		dealer  string
+		want    []WinnerShare
+	}{
+		{
+			name:    "even split",
+			amount:  300,
+			winners: []string{"a", "c", "d"},
+			dealer:  "b",
+			want:    []WinnerShare{{UserID: "c", Amount: 100}, {UserID: "d", Amount: 100}, {UserID: "a", Amount: 100}},
+		},
+		{
+			// Баттон среди победителей: лишняя фишка — первому слева, баттон последний
+			name:    "button seat wins a share",
+			amount:  100,
+			winners: []string{"a", "b", "c"},
+			dealer:  "a",
+			want:    []WinnerShare{{UserID: "b", Amount: 34}, {UserID: "c", Amount: 33}, {UserID: "a", Amount: 33}},
+		},
+		{
+			// Баттон на последнем месте: отсчёт переходит на начало стола
+			name:    "wrap-around from the last seat",
+			amount:  100,
+			winners: []string{"d", "c", "a"},
+			dealer:  "d",
+			want:    []WinnerShare{{UserID: "a", Amount: 34}, {UserID: "c", Amount: 33}, {UserID: "d", Amount: 33}},
+		},
+		{
+			name:    "two odd chips",
+			amount:  101,
+			winners: []string{"a", "b", "d"},
+			dealer:  "c",
+			want:    []WinnerShare{{UserID: "d", Amount: 34}, {UserID: "a", Amount: 34}, {UserID: "b", Amount: 33}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitPot(tt.amount, tt.winners, order, tt.dealer)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SplitPot = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func parseCards(t *testing.T, s string) []cards.Card {
+	t.Helper()
+	var hand []cards.Card
+	for _, f := range strings.Fields(s) {
+		c, err := cards.Parse(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hand = append(hand, c)
+	}
+	return hand
+}
+
+// showdownWorkflow — resolveShowdown шлёт сообщения активностями, поэтому работает в воркфлоу
+func showdownWorkflow(ctx workflow.Context, state RoomState) ([]PotResult, error) {
+	return resolveShowdown(ctx, &state), nil
+}
+
+// Хай-лоу холдем: банк делится пополам между хаем и лоу восемь-или-лучше
+func TestResolveShowdownHiLo(t *testing.T) {
+	tests := []struct {
+		name          string
+		board         string
+		hands         map[string]string
+		contributions map[string]int64
+		folded        map[string]bool
+		want          []WinnerShare
+		scoop         bool
+	}{
+		{
+			// На борде две низкие карты — лоу не собрать никому, хай забирает весь банк
+			name:          "no qualifying low",
+			board:         "Ks Qd 9h 8c 2s",
+			hands:         map[string]string{"a": "Ah Kd", "b": "Qs Jc", "c": "4c 3d"},
+			contributions: map[string]int64{"a": 100, "b": 100, "c": 100},
+			want:          []WinnerShare{{UserID: "a", Amount: 300, Side: "hi"}},
+			scoop:         true,
+		},
+		{
+			// Стрит до семёрки и лоу 6-5-4-3-2 у одного игрока
+			name:          "scoop",
+			board:         "7s 6d 5h Kc 2s",
+			hands:         map[string]string{"a": "4c 3d", "b": "Kd Qh"},
+			contributions: map[string]int64{"a": 100, "b": 100},
+			want:          []WinnerShare{{UserID: "a", Amount: 100, Side: "hi"}, {UserID: "a", Amount: 100, Side: "lo"}},
+			scoop:         true,
+		},
+		{
+			// Одинаковый лоу у двоих: каждому по четверти банка
+			name:          "quartered low",
+			board:         "7s 6d 5h Kc 2s",
+			hands:         map[string]string{"a": "Ah 3c", "b": "Ad 3h", "c": "Ks Kh"},
+			contributions: map[string]int64{"a": 100, "b": 100, "c": 100},
+			want: []WinnerShare{
+				{UserID: "c", Amount: 150, Side: "hi"},
+				{UserID: "a", Amount: 75, Side: "lo"},
+				{UserID: "b", Amount: 75, Side: "lo"},
+			},
+		},
+		{
+			// Нечётный банк 201: лишняя фишка — хай-половине
+			name:          "odd chip goes high",
+			board:         "7s 6d 5h Kc 2s",
+			hands:         map[string]string{"a": "Ks Kh", "b": "Ah 3c", "c": "Qs Qh"},
+			contributions: map[string]int64{"a": 67, "b": 67, "c": 67},
+			folded:        map[string]bool{"c": true},
+			want:          []WinnerShare{{UserID: "a", Amount: 101, Side: "hi"}, {UserID: "b", Amount: 100, Side: "lo"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := RoomState{Game: variant.HoldemHiLo, Dealer: "c", Hand: newHand(1, time.Time{}, "river")}
+			state.Hand.PlayerOrder = []string{"a", "b", "c"}
+			state.Hand.BoardCards = parseCards(t, tt.board)
+			for id, hand := range tt.hands {
+				state.Hand.PlayerCards[id] = parseCards(t, hand)
+				state.Hand.PlayerFolded[id] = tt.folded[id]
+			}
+			state.Hand.Contributions = tt.contributions
+
+			var suite testsuite.WorkflowTestSuite
+			env := suite.NewTestWorkflowEnvironment()
+			env.RegisterWorkflow(showdownWorkflow)
+			env.ExecuteWorkflow(showdownWorkflow, state)
+			var results []PotResult
+			if err := env.GetWorkflowResult(&results); err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("got %d pots, want 1: %+v", len(results), results)
+			}
+
+			got := results[0].Winners
+			for i := range got {
+				got[i].Hand = ""
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("winners = %+v, want %+v", got, tt.want)
+			}
+			if results[0].Scoop != tt.scoop {
+				t.Fatalf("scoop = %v, want %v", results[0].Scoop, tt.scoop)
+			}
+		})
+	}
+}
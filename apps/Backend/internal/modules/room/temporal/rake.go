@@ -0,0 +1,121 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/log"
+	"go.uber.org/zap"
+	"math"
+	"poker/internal/modules/room/variant"
+	"poker/packages/database"
+)
+
+// maxRakePercent — больше 10% банка рейк не бывает
+const maxRakePercent = 10
+
+// RakeSettings — рейк кэш-стола: доля каждого банка в сотых долях процента и потолок за раздачу
+type RakeSettings struct {
+	BasisPoints int64
+	Cap         int64 // 0 — без потолка
+}
+
+func (r RakeSettings) Enabled() bool {
+	return r.BasisPoints > 0
+}
+
+// ParseRake проверяет настройки рейка. Рейк берут только за кэш-столами
+func ParseRake(tableType string, percent float64, cap int64) (RakeSettings, error) {
+	if percent < 0 || percent > maxRakePercent || math.IsNaN(percent) {
+		return RakeSettings{}, fmt.Errorf("invalid rake %.2f%%: must be between 0 and %d%%", percent, maxRakePercent)
+	}
+	if cap < 0 {
+		return RakeSettings{}, fmt.Errorf("invalid rake cap %d: must not be negative", cap)
+	}
+	if percent > 0 && tableType != "cash" {
+		return RakeSettings{}, fmt.Errorf("rake is only taken at cash tables, got %q", tableType)
+	}
+	return RakeSettings{BasisPoints: int64(math.Round(percent * 100)), Cap: cap}, nil
+}
+
+// roomRake — рейк комнаты; при неверных значениях стол играет без рейка
+func roomRake(room database.Room, logger log.Logger) RakeSettings {
+	r, err := ParseRake(room.Type, room.RakePercent, room.RakeCap)
+	if err != nil {
+		logger.Warn("⚠️ Invalid room rake, playing without rake", zap.Error(err))
+		return RakeSettings{}
+	}
+	return r
+}
+
+// takeRake снимает рейк с банков раздачи до их дележа: процент с каждого банка,
+// начиная с основного, пока не набран потолок. Если раздача не дошла до флопа
+// (до второй улицы в стаде и дро), рейка нет. Несколлированная ставка не облагается
+func takeRake(state *RoomState) {
+	state.Hand.Rake = 0
+	if !state.Rake.Enabled() || state.Hand.RoundStage == variant.Get(state.Game).Streets[0].Name {
+		return
+	}
+
+	uncalled := uncalledBet(state.Hand.Contributions)
+	for i := range state.Hand.Pots {
+		pot := &state.Hand.Pots[i]
+		rakeable := pot.Amount
+		// Лишнее сверх остальных вложил только самый крупный игрок — это последний банк
+		if i == len(state.Hand.Pots)-1 {
+			rakeable -= uncalled
+		}
+		if rakeable <= 0 {
+			continue
+		}
+
+		rake := rakeable * state.Rake.BasisPoints / 10000
+		if state.Rake.Cap > 0 && state.Hand.Rake+rake > state.Rake.Cap {
+			rake = state.Rake.Cap - state.Hand.Rake
+		}
+		pot.Rake = rake
+		state.Hand.Rake += rake
+	}
+}
+
+// uncalledBet — сколько самый крупный вклад превышает второй по величине
+func uncalledBet(contributions map[string]int64) int64 {
+	var first, second int64
+	for _, amount := range contributions {
+		switch {
+		case amount > first:
+			first, second = amount, first
+		case amount > second:
+			second = amount
+		}
+	}
+	return first - second
+}
+
+// rakeShares — сколько рейка пришлось на каждого победителя: рейк банка делится
+// между его победителями пропорционально их долям, остаток — по одной фишке с первого
+func rakeShares(results []PotResult) map[string]int64 {
+	shares := make(map[string]int64)
+	for _, r := range results {
+		if r.Rake == 0 {
+			continue
+		}
+		var won int64
+		for _, w := range r.Winners {
+			won += w.Amount
+		}
+		if won == 0 {
+			continue
+		}
+
+		rest := r.Rake
+		for _, w := range r.Winners {
+			part := r.Rake * w.Amount / won
+			shares[w.UserID] += part
+			rest -= part
+		}
+		for i := 0; rest > 0; i = (i + 1) % len(r.Winners) {
+			shares[r.Winners[i].UserID]++
+			rest--
+		}
+	}
+	return shares
+}
@@ -0,0 +1,101 @@
+package room_temporal
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.temporal.io/sdk/workflow"
+	"poker/internal/modules/room/cards"
+	"poker/packages/database"
+	"time"
+)
+
+// ReplayFrame — стол после очередного шага раздачи. Ключи те же, что в update-game-state,
+// чтобы фронтенд проигрывал повтор так же, как живую игру
+type ReplayFrame struct {
+	ActionSeq      int                     `json:"-"`
+	Street         string                  `json:"street"`
+	Players        []string                `json:"players"`
+	Pot            int64                   `json:"pot"`
+	Pots           []SidePot               `json:"pots"`
+	CommunityCards []cards.Card            `json:"communityCards"`
+	Stacks         map[string]int64        `json:"stacks"`
+	Bets           map[string]int64        `json:"bets"`
+	Folded         map[string]bool         `json:"folded"`
+	AllIn          map[string]bool         `json:"allIn"`
+	UpCards        map[string][]cards.Card `json:"upCards"`
+	CurrentTurn    string                  `json:"currentTurn"`
+	Drawing        bool                    `json:"drawing"`
+	Winners        []WinnerShare           `json:"winners,omitempty"`
+	Rake           int64                   `json:"rake"`
+	PlayerCards    map[string][]cards.Card `json:"-"` // закрытые карты — отдельно, их видно не всем
+	At             time.Time               `json:"-"`
+}
+
+// recordFrame запоминает, как выглядит стол сейчас. Вызывается после раздачи карт,
+// после каждого действия и в конце раздачи
+func recordFrame(ctx workflow.Context, state *RoomState) {
+	hand := &state.Hand
+	frame := ReplayFrame{
+		ActionSeq:      len(hand.Actions),
+		Street:         hand.RoundStage,
+		Players:        append([]string(nil), hand.PlayerOrder...),
+		Pot:            hand.Pot,
+		Pots:           currentPots(state),
+		CommunityCards: append([]cards.Card(nil), hand.BoardCards...),
+		Stacks:         make(map[string]int64, len(hand.PlayerOrder)),
+		Bets:           make(map[string]int64, len(hand.PlayerOrder)),
+		Folded:         make(map[string]bool, len(hand.PlayerOrder)),
+		AllIn:          make(map[string]bool, len(hand.PlayerOrder)),
+		UpCards:        make(map[string][]cards.Card, len(hand.UpCards)),
+		CurrentTurn:    hand.CurrentPlayer,
+		Drawing:        hand.Drawing,
+		Rake:           hand.Rake,
+		PlayerCards:    make(map[string][]cards.Card, len(hand.PlayerCards)),
+		At:             workflow.Now(ctx),
+	}
+	if hand.RoundStage == "ended" {
+		frame.Street = hand.EndStreet
+		frame.Pots = hand.Pots
+		frame.Winners = hand.Winners
+	}
+	for _, id := range hand.PlayerOrder {
+		frame.Stacks[id] = state.PlayerChips[id]
+		frame.Bets[id] = hand.PlayerBets[id]
+		frame.Folded[id] = hand.PlayerFolded[id]
+		frame.AllIn[id] = hand.PlayerAllIn[id]
+	}
+	// Карты копируются: в дро они меняются прямо в срезах раздачи
+	for id, cs := range hand.UpCards {
+		frame.UpCards[id] = append([]cards.Card(nil), cs...)
+	}
+	for id, cs := range hand.PlayerCards {
+		frame.PlayerCards[id] = append([]cards.Card(nil), cs...)
+	}
+	hand.Replay = append(hand.Replay, frame)
+}
+
+// replaySnapshots — кадры повтора в строки таблицы game_snapshots
+func replaySnapshots(id string, frames []ReplayFrame) ([]database.GameSnapshot, error) {
+	snapshots := make([]database.GameSnapshot, 0, len(frames))
+	for i, f := range frames {
+		public, err := json.Marshal(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode replay frame %d: %w", i, err)
+		}
+		hidden, err := json.Marshal(f.PlayerCards)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode replay cards %d: %w", i, err)
+		}
+		snapshots = append(snapshots, database.GameSnapshot{
+			ID:        fmt.Sprintf("%s/frame-%d", id, i),
+			GameID:    id,
+			Seq:       i,
+			ActionSeq: f.ActionSeq,
+			Street:    f.Street,
+			State:     string(public),
+			Cards:     string(hidden),
+			CreatedAt: f.At.Unix(),
+		})
+	}
+	return snapshots, nil
+}
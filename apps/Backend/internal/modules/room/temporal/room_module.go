@@ -6,6 +6,8 @@ import (
 	"go.uber.org/zap"
 	"poker/internal/modules/auth/repo"
 	authService "poker/internal/modules/auth/service"
+	historyRepo "poker/internal/modules/history/repo"
+	roomRepo "poker/internal/modules/room/repo"
 	"poker/packages/database"
 )
 
@@ -25,7 +27,11 @@ func (m *RoomModule) Init(c client.Client) error {
 	authRepo := repo.NewAuthRepo(db)
 	authSvc := authService.NewAuthService(authRepo, m.logger)
 
-	m.activities = &RoomActivities{AuthService: authSvc}
+	m.activities = &RoomActivities{
+		AuthService: authSvc,
+		RoomRepo:    roomRepo.NewRoomRepo(db),
+		HistoryRepo: historyRepo.NewHistoryRepo(db),
+	}
 	defaultRoomActivities = m.activities
 	return nil
 }
@@ -37,9 +43,10 @@ func (m *RoomModule) TaskQueue() string {
 func (m *RoomModule) Register(w worker.Worker) {
 	w.RegisterWorkflow(StartRoomWorkflow)
 
-	w.RegisterActivity(GetPlayerBalanceActivity)
-	w.RegisterActivity(DeductChipsFromBalanceActivity)
-	w.RegisterActivity(CreditWinningsActivity)
+	w.RegisterActivity(GetRoomActivity)
+	w.RegisterActivity(BuyInActivity)
+	w.RegisterActivity(CashOutActivity)
+	w.RegisterActivity(SettleHandActivity)
 	w.RegisterActivity(UpdateUserElo)
 
 	w.RegisterActivity(SendMessageActivity)
@@ -0,0 +1,228 @@
+package room_temporal
+
+import (
+	"fmt"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+	"poker/internal/modules/room/variant"
+	"poker/packages/database"
+	"strings"
+)
+
+// MaxRotationHands — дольше одна игра смешанного стола не длится
+const MaxRotationHands = 100
+
+// RotationGame — игра смешанного стола со своей структурой ставок
+type RotationGame struct {
+	Game    variant.GameType
+	Betting variant.BettingStructure
+}
+
+func (g RotationGame) String() string {
+	return fmt.Sprintf("%s %s", g.Betting, g.Game)
+}
+
+// Rotation — смена игр за столом. Без игр — обычный стол одной игры
+type Rotation struct {
+	Games         []RotationGame
+	Hands         int  // раздач на каждую игру; 0 — круг, по раздаче на каждое место
+	DealersChoice bool // следующую игру выбирает баттон
+}
+
+func (r Rotation) Mixed() bool { return len(r.Games) > 0 }
+
+// rotationPresets — известные смешанные форматы. Весь HOSE играется в лимит
+var rotationPresets = map[string][]RotationGame{
+	"hose": {
+		{Game: variant.Holdem, Betting: variant.FixedLimit},
+		{Game: variant.OmahaHiLo, Betting: variant.FixedLimit},
+		{Game: variant.Stud, Betting: variant.FixedLimit},
+		{Game: variant.StudHiLo, Betting: variant.FixedLimit},
+	},
+}
+
+// ParseRotation разбирает Room.Rotation: название формата ("hose") или список игр через запятую,
+// у каждой можно указать структуру ставок — "holdem:no-limit,omaha,stud". Без структуры берётся
+// привычная для игры. Пустой список — одна игра, а в дилерс-чойсе — выбор из всех игр
+func ParseRotation(games string, hands int, dealersChoice bool) (Rotation, error) {
+	r := Rotation{Hands: hands, DealersChoice: dealersChoice}
+	if hands < 0 || hands > MaxRotationHands {
+		return Rotation{}, fmt.Errorf("invalid rotation hands %d: expected 0..%d", hands, MaxRotationHands)
+	}
+
+	games = strings.ToLower(strings.TrimSpace(games))
+	switch preset, ok := rotationPresets[games]; {
+	case ok:
+		r.Games = append(r.Games, preset...)
+	case games != "":
+		for _, entry := range strings.Split(games, ",") {
+			game, err := parseRotationGame(entry)
+			if err != nil {
+				return Rotation{}, err
+			}
+			r.Games = append(r.Games, game)
+		}
+	case dealersChoice:
+		// Пока баттон не выбрал, играется холдем
+		r.Games = append(r.Games, RotationGame{Game: variant.DefaultGameType, Betting: variant.Get(variant.DefaultGameType).DefaultBetting})
+		for _, name := range variant.GameTypeNames() {
+			if t := variant.GameType(name); t != variant.DefaultGameType {
+				r.Games = append(r.Games, RotationGame{Game: t, Betting: variant.Get(t).DefaultBetting})
+			}
+		}
+	}
+	return r, nil
+}
+
+func parseRotationGame(entry string) (RotationGame, error) {
+	name, structure, _ := strings.Cut(strings.TrimSpace(entry), ":")
+	if strings.TrimSpace(name) == "" {
+		return RotationGame{}, fmt.Errorf("invalid rotation %q: empty game", entry)
+	}
+	game, err := variant.ParseGameType(name)
+	if err != nil {
+		return RotationGame{}, err
+	}
+	betting := variant.Get(game).DefaultBetting
+	if strings.TrimSpace(structure) != "" {
+		if betting, err = variant.ParseBettingStructure(structure); err != nil {
+			return RotationGame{}, err
+		}
+	}
+	return RotationGame{Game: game, Betting: betting}, nil
+}
+
+// MaxPlayers — сколько игроков вмещают все игры ротации; 0 — без ограничений
+func (r Rotation) MaxPlayers() int {
+	max := 0
+	for _, g := range r.Games {
+		if m := variant.Get(g.Game).MaxPlayers; m > 0 && (max == 0 || m < max) {
+			max = m
+		}
+	}
+	return max
+}
+
+// find — позиция игры в ротации; -1, если такой нет
+func (r Rotation) find(game variant.GameType) int {
+	for i, g := range r.Games {
+		if g.Game == game {
+			return i
+		}
+	}
+	return -1
+}
+
+// roomRotation — ротация комнаты; при неверных настройках стол играет одну игру
+func roomRotation(room database.Room, logger log.Logger) Rotation {
+	r, err := ParseRotation(room.Rotation, room.RotationHands, room.DealersChoice)
+	if err != nil {
+		logger.Warn("⚠️ Invalid room rotation, playing a single game", zap.Error(err))
+		return Rotation{}
+	}
+	return r
+}
+
+// MixedState — где стол находится в ротации
+type MixedState struct {
+	Position   int              // текущая игра в Rotation.Games
+	HandsLeft  int              // сколько раздач ещё играется текущая игра
+	ChosenGame variant.GameType // выбор баттона в дилерс-чойсе; пусто — пока не выбрано
+}
+
+// rotateGame вызывается перед каждой раздачей: когда текущая игра отыграла своё,
+// стол переходит к следующей по списку или к выбранной баттоном
+func rotateGame(ctx workflow.Context, state *RoomState) {
+	r := state.Rotation
+	if !r.Mixed() {
+		return
+	}
+
+	if state.Mixed.HandsLeft <= 0 {
+		next := state.Mixed.Position
+		switch {
+		case state.Mixed.ChosenGame != "":
+			next = r.find(state.Mixed.ChosenGame)
+		case state.HandNumber > 0 && !r.DealersChoice:
+			next = (next + 1) % len(r.Games)
+		}
+		// В дилерс-чойсе без выбора игра не меняется
+		state.Mixed.ChosenGame = ""
+		state.Mixed.HandsLeft = rotationLength(state)
+
+		changed := state.HandNumber == 0 || next != state.Mixed.Position
+		state.Mixed.Position = next
+		state.Game = r.Games[next].Game
+		state.Betting = r.Games[next].Betting
+		if changed {
+			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🔄 Now playing %s for %d hands", r.Games[next], state.Mixed.HandsLeft))
+		}
+	}
+	state.Mixed.HandsLeft--
+}
+
+// rotationLength — сколько раздач длится игра: заданное число или круг по занятым местам
+func rotationLength(state *RoomState) int {
+	if state.Rotation.Hands > 0 {
+		return state.Rotation.Hands
+	}
+	if len(state.Seats) > 0 {
+		return len(state.Seats)
+	}
+	return 1
+}
+
+// chooseGame — выбор баттона в дилерс-чойсе. Игра начнётся, когда закончится текущая
+func chooseGame(state *RoomState, userID, game string) (RotationGame, error) {
+	if !state.Rotation.DealersChoice {
+		return RotationGame{}, fmt.Errorf("this table is not dealer's choice")
+	}
+	if userID != state.Dealer {
+		return RotationGame{}, fmt.Errorf("only the button can choose the next game")
+	}
+	t, err := variant.ParseGameType(game)
+	if err != nil {
+		return RotationGame{}, err
+	}
+	i := state.Rotation.find(t)
+	if i < 0 {
+		return RotationGame{}, fmt.Errorf("%s is not in this table's rotation", t)
+	}
+	state.Mixed.ChosenGame = t
+	return state.Rotation.Games[i], nil
+}
+
+// rotationPayload — текущая игра и ближайшие в ротации для клиентов
+func rotationPayload(state RoomState) map[string]interface{} {
+	r := state.Rotation
+	if !r.Mixed() {
+		return nil
+	}
+
+	upcoming := make([]string, 0, len(r.Games))
+	switch {
+	case state.Mixed.ChosenGame != "":
+		upcoming = append(upcoming, r.Games[r.find(state.Mixed.ChosenGame)].String())
+	case !r.DealersChoice:
+		for i := 1; i < len(r.Games); i++ {
+			upcoming = append(upcoming, r.Games[(state.Mixed.Position+i)%len(r.Games)].String())
+		}
+	}
+
+	games := make([]string, 0, len(r.Games))
+	for _, g := range r.Games {
+		games = append(games, g.String())
+	}
+	payload := map[string]interface{}{
+		"games":         games,
+		"current":       r.Games[state.Mixed.Position].String(),
+		"handsLeft":     state.Mixed.HandsLeft,
+		"upcoming":      upcoming,
+		"dealersChoice": r.DealersChoice,
+	}
+	if r.DealersChoice {
+		payload["chooser"] = state.Dealer
+	}
+	return payload
+}
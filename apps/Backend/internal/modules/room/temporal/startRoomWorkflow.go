@@ -6,7 +6,7 @@ import (
 	"go.temporal.io/sdk/workflow"
 	"go.uber.org/zap"
 	"poker/internal/modules/room/manager"
-	"strings"
+	"poker/internal/modules/room/variant"
 	"time"
 )
 
@@ -26,53 +26,100 @@ type PlayerReadySignal struct {
 	Ready  bool
 }
 type TerminateGameSignal struct{}
+type ClientSeedSignal struct {
+	UserID string
+	Seed   string
+}
+type ChooseGameSignal struct {
+	UserID string
+	Game   string
+}
 type PlayerMoveSignal struct {
 	UserID string
 	Action string
 	Args   []string
 }
 
+// RoomState — состояние стола, живёт всё время работы воркфлоу
 type RoomState struct {
-	RoomID        string
-	Players       map[string]bool
-	StartTime     time.Time
-	PlayerOrder   []string // порядок по кругу
-	PlayerChips   map[string]int64
-	PlayerFolded  map[string]bool
-	PlayerAllIn   map[string]bool
-	CurrentPlayer string
-	CurrentBet    int64
-	LastRaise     int64
-	Pot           int64
-	MoveLog       []string
-	GameStarted   bool
-	PlayerCards   map[string][]string
-	Deck          []string
-	BoardCards    []string
-	RoundStage    string
-	HasActed      map[string]bool
-	PlayerBets    map[string]int64
-	ReadyPlayers  map[string]bool
-	Terminated    bool
+	RoomID       string
+	Players      map[string]bool // подключённые игроки
+	StartTime    time.Time
+	Seats        []string         // игроки за столом в порядке посадки
+	PlayerChips  map[string]int64 // стеки, переносятся из раздачи в раздачу
+	GameStarted  bool             // идёт сессия: раздачи сдаются одна за другой
+	ReadyPlayers map[string]bool
+	Terminated   bool
+
+	Blinds    Blinds
+	BuyIn     BuyInLimits
+	Rake      RakeSettings
+	Game      variant.GameType
+	Betting   variant.BettingStructure
+	Rotation  Rotation // смешанный стол: игры сменяются по ходу сессии
+	Mixed     MixedState
+	Clock     ClockSettings
+	TimeBanks map[string]time.Duration // банк времени каждого игрока
+
+	// Честное тасование: хэш сида следующей раздачи известен заранее, игроки добавляют свои сиды
+	NextServerSeed string
+	NextCommitment string
+	ClientSeeds    map[string]string
+
+	Dealer            string // баттон
+	HandNumber        int
+	LedgerSeq         int                    // номер последней операции с фишками, для ключей идемпотентности
+	PendingBuyIns     map[string]EscrowInput // бай-ины без окончательного ответа: повтор идёт с тем же ключом и суммой
+	CashOutKeys       map[string]string      // ключи неудавшихся расчётов: повтор идёт с тем же ключом
+	PendingSettlement *SettlementInput       // итог раздачи, ещё не проведённый в книге
+	Hand              HandState              // текущая (или последняя сыгранная) раздача
 }
 
-func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
+// StartRoomWorkflow ведёт стол. carried — состояние, перенесённое из прошлого запуска
+// через continue-as-new; при открытии стола оно nil
+func StartRoomWorkflow(ctx workflow.Context, roomID string, carried *RoomState) error {
 	baseCtx := ctx
 	logger := workflow.GetLogger(baseCtx)
 
-	state := &RoomState{
-		RoomID:    roomID,
-		Players:   make(map[string]bool),
-		MoveLog:   []string{},
-		StartTime: workflow.Now(baseCtx),
+	state := carried
+	if state != nil {
+		restoreState(state)
+		logger.Info("🔁 Room continued as new run", zap.Int("hand", state.HandNumber), zap.Int("seats", len(state.Seats)))
+	} else {
+		state = &RoomState{
+			RoomID:        roomID,
+			Players:       make(map[string]bool),
+			PlayerChips:   make(map[string]int64),
+			TimeBanks:     make(map[string]time.Duration),
+			ClientSeeds:   make(map[string]string),
+			PendingBuyIns: make(map[string]EscrowInput),
+			CashOutKeys:   make(map[string]string),
+			StartTime:     workflow.Now(baseCtx),
+		}
+		room := loadRoom(baseCtx, roomID, logger)
+		state.Blinds = roomBlinds(room, logger)
+		state.BuyIn = roomBuyIn(room, state.Blinds, logger)
+		state.Rake = roomRake(room, logger)
+		state.Game = roomGameType(room, logger)
+		state.Betting = roomBettingStructure(room, logger)
+		state.Clock = roomClock(room, logger)
+		state.Rotation = roomRotation(room, logger)
+		prepareServerSeed(baseCtx, state)
+		if state.Rotation.Mixed() {
+			state.Game = state.Rotation.Games[0].Game
+			state.Betting = state.Rotation.Games[0].Betting
+		}
 	}
+	firstHand := state.HandNumber
 
 	var (
 		cancelTimer      workflow.CancelFunc
 		emptyRoomTimer   workflow.Future
 		readyTimer       workflow.Future
 		cancelReadyTimer workflow.CancelFunc
-		hasHadPlayers    bool
+		nextHandTimer    workflow.Future
+		turnClock        actionClock
+		hasHadPlayers    = carried != nil
 	)
 
 	startGameChan := workflow.GetSignalChannel(baseCtx, "start-game")
@@ -82,6 +129,9 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 	terminateChan := workflow.GetSignalChannel(baseCtx, "terminate-game")
 	dealCardsChan := workflow.GetSignalChannel(baseCtx, "deal-cards")
 	readyChan := workflow.GetSignalChannel(baseCtx, "player-ready")
+	chooseGameChan := workflow.GetSignalChannel(baseCtx, "choose-game")
+	clientSeedChan := workflow.GetSignalChannel(baseCtx, "client-seed")
+	buyInChan := workflow.GetSignalChannel(baseCtx, "buy-in")
 	internalStartGameChan := workflow.NewBufferedChannel(baseCtx, 1)
 
 	tick := time.Minute * 2
@@ -93,35 +143,15 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 		return GetAvailableActions(state, userID), nil
 	})
 
-	for {
-		if state.Terminated {
-			break
-		}
-
-		if hasHadPlayers && len(state.Players) == 0 && emptyRoomTimer == nil {
-			logger.Info("⌛ No players — starting auto-termination")
-			var cancelCtx workflow.Context
-			cancelCtx, cancelTimer = workflow.WithCancel(baseCtx)
-			emptyRoomTimer = workflow.NewTimer(cancelCtx, 30*time.Second)
-		}
-
-		selector := workflow.NewSelector(baseCtx)
-
-		if emptyRoomTimer != nil {
-			selector.AddFuture(emptyRoomTimer, func(f workflow.Future) {
-				logger.Info("🛑 Auto-termination timeout")
-				state.Terminated = true
-			})
-		}
-
-		if readyTimer != nil {
-			selector.AddFuture(readyTimer, func(f workflow.Future) {
-				logger.Info("✅ All players ready — triggering internal start")
-				internalStartGameChan.Send(baseCtx, struct{}{})
-				readyTimer = nil
-			})
+	_ = workflow.SetQueryHandler(baseCtx, "action-options", func(userID string) ([]ActionOption, error) {
+		if _, ok := state.Players[userID]; !ok {
+			return nil, fmt.Errorf("player %s not in room", userID)
 		}
+		return GetActionOptions(state, userID), nil
+	})
 
+	// Обработчики сигналов общие для витка цикла и для разбора сигналов перед continue-as-new
+	addSignalHandlers := func(selector workflow.Selector) {
 		selector.AddReceive(joinChan, func(c workflow.ReceiveChannel, _ bool) {
 			var s JoinRoomSignal
 			c.Receive(baseCtx, &s)
@@ -131,6 +161,7 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 			}
 
 			state.Players[s.UserID] = true
+			seatPlayer(state, s.UserID)
 			hasHadPlayers = true
 
 			if emptyRoomTimer != nil {
@@ -146,8 +177,10 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 			var s LeaveRoomSignal
 			c.Receive(baseCtx, &s)
 			delete(state.Players, s.UserID)
+			delete(state.ReadyPlayers, s.UserID)
 			logger.Info("👋 Player left", zap.String("userID", s.UserID))
 			sendToAllPlayers(baseCtx, roomID, state.Players, fmt.Sprintf("👋 Player %s left", s.UserID))
+			handleLeave(baseCtx, state, s.UserID)
 		})
 
 		selector.AddReceive(startGameChan, func(c workflow.ReceiveChannel, _ bool) {
@@ -231,41 +264,44 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 				return
 			}
 
-			entry := fmt.Sprintf("%s: %s %s", s.UserID, s.Action, strings.Join(s.Args, " "))
-			state.MoveLog = append(state.MoveLog, entry)
-			sendToAllPlayers(baseCtx, roomID, state.Players, entry)
-
-			handler := ActionRegistry[s.Action]
-			oldChips := state.PlayerChips[s.UserID]
-			handler.Execute(state, s.UserID, s.Args)
-			newChips := state.PlayerChips[s.UserID]
-			if newChips < oldChips {
-				amountSpent := oldChips - newChips
-				actCtx := workflow.WithActivityOptions(baseCtx, workflow.ActivityOptions{
-					StartToCloseTimeout: 5 * time.Second,
-				})
-				err := workflow.ExecuteActivity(actCtx, DeductChipsFromBalanceActivity, BalanceUpdateInput{
-					UserID: s.UserID,
-					Amount: amountSpent,
-				}).Get(actCtx, nil)
-				if err != nil {
-					logger.Error("❌ Failed to deduct balance", zap.String("userID", s.UserID), zap.Error(err))
-				}
+			spendTimeBank(baseCtx, state, s.UserID)
+			applyMove(baseCtx, state, s.UserID, s.Action, s.Args)
+		})
+
+		selector.AddReceive(chooseGameChan, func(c workflow.ReceiveChannel, _ bool) {
+			var s ChooseGameSignal
+			c.Receive(baseCtx, &s)
+
+			game, err := chooseGame(state, s.UserID, s.Game)
+			if err != nil {
+				sendToPlayer(baseCtx, roomID, s.UserID, "❌ Invalid choice: "+err.Error())
+				return
 			}
-			state.HasActed[s.UserID] = true
+			logger.Info("🎲 Dealer's choice", zap.String("userID", s.UserID), zap.String("game", string(game.Game)))
+			sendToAllPlayers(baseCtx, roomID, state.Players, fmt.Sprintf("🎲 %s chose %s", s.UserID, game))
+		})
 
-			if IsBettingRoundOver(state) {
-				NextTurn(baseCtx, state)
-				if !state.GameStarted {
-					return
-				}
-				NextStage(state)
-				DealBoardCards(state)
-				sendToAllPlayers(baseCtx, roomID, state.Players, "🃏 New stage: "+state.RoundStage)
-				NextTurn(baseCtx, state)
-			} else {
-				NextTurn(baseCtx, state)
+		selector.AddReceive(buyInChan, func(c workflow.ReceiveChannel, _ bool) {
+			var s BuyInSignal
+			c.Receive(baseCtx, &s)
+
+			if err := buyIn(baseCtx, state, s.UserID, s.Amount); err != nil {
+				sendToPlayer(baseCtx, roomID, s.UserID, "❌ Invalid buy-in: "+err.Error())
+				return
+			}
+			logger.Info("💰 Buy-in", zap.String("userID", s.UserID), zap.Int64("amount", s.Amount))
+			sendToAllPlayers(baseCtx, roomID, state.Players, fmt.Sprintf("💰 %s bought in for %d", s.UserID, s.Amount))
+		})
+
+		selector.AddReceive(clientSeedChan, func(c workflow.ReceiveChannel, _ bool) {
+			var s ClientSeedSignal
+			c.Receive(baseCtx, &s)
+
+			if err := setClientSeed(state, s.UserID, s.Seed); err != nil {
+				sendToPlayer(baseCtx, roomID, s.UserID, "❌ Invalid seed: "+err.Error())
+				return
 			}
+			sendToPlayer(baseCtx, roomID, s.UserID, "🔐 Your seed will be used from the next hand")
 		})
 
 		selector.AddReceive(terminateChan, func(c workflow.ReceiveChannel, _ bool) {
@@ -288,10 +324,89 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 				logger.Info("✅ All players notified about termination")
 			}
 
+			// Недоигранная раздача отменяется: фишки из банка возвращаются в стеки
+			if handInProgress(state) {
+				returnPot(state)
+			}
 			state.GameStarted = false
-			state.RoundStage = "ended"
+			state.Hand.RoundStage = "ended"
 			state.Terminated = true
 		})
+	}
+
+	for {
+		if state.Terminated {
+			break
+		}
+
+		// Между раздачами стол переходит в новый запуск. Сигналы, которые уже пришли,
+		// сначала обрабатываются: иначе они пропали бы вместе со старым запуском
+		if continueAsNewDue(baseCtx, state, firstHand) && emptyRoomTimer == nil && readyTimer == nil {
+			drain := workflow.NewSelector(baseCtx)
+			addSignalHandlers(drain)
+			if !drain.HasPending() {
+				logger.Info("🔁 Continuing room as new run",
+					zap.Int("hand", state.HandNumber),
+					zap.Int("historyLength", workflow.GetInfo(baseCtx).GetCurrentHistoryLength()))
+				return workflow.NewContinueAsNewError(baseCtx, StartRoomWorkflow, roomID, carryOver(state))
+			}
+			for drain.HasPending() {
+				drain.Select(baseCtx)
+			}
+			// Сигналы могли начать раздачу или запустить таймеры — решение принимается заново
+			turnClock.sync(baseCtx, state)
+			sendGameState(baseCtx, state)
+			continue
+		}
+
+		if hasHadPlayers && len(state.Players) == 0 && emptyRoomTimer == nil {
+			logger.Info("⌛ No players — starting auto-termination")
+			var cancelCtx workflow.Context
+			cancelCtx, cancelTimer = workflow.WithCancel(baseCtx)
+			emptyRoomTimer = workflow.NewTimer(cancelCtx, 30*time.Second)
+		}
+
+		// Раздача сыграна — через паузу сдаём следующую
+		if state.GameStarted && !handInProgress(state) && nextHandTimer == nil {
+			nextHandTimer = workflow.NewTimer(baseCtx, nextHandDelay)
+		}
+
+		selector := workflow.NewSelector(baseCtx)
+
+		if turnClock.timer != nil {
+			selector.AddFuture(turnClock.timer, func(f workflow.Future) {
+				if err := f.Get(baseCtx, nil); err != nil {
+					return // таймер отменён
+				}
+				turnClock.expire(baseCtx, state)
+			})
+		}
+
+		if nextHandTimer != nil {
+			selector.AddFuture(nextHandTimer, func(f workflow.Future) {
+				nextHandTimer = nil
+				if state.GameStarted && !handInProgress(state) {
+					startHand(baseCtx, state)
+				}
+			})
+		}
+
+		if emptyRoomTimer != nil {
+			selector.AddFuture(emptyRoomTimer, func(f workflow.Future) {
+				logger.Info("🛑 Auto-termination timeout")
+				state.Terminated = true
+			})
+		}
+
+		if readyTimer != nil {
+			selector.AddFuture(readyTimer, func(f workflow.Future) {
+				logger.Info("✅ All players ready — triggering internal start")
+				internalStartGameChan.Send(baseCtx, struct{}{})
+				readyTimer = nil
+			})
+		}
+
+		addSignalHandlers(selector)
 
 		selector.AddFuture(workflow.NewTimer(baseCtx, tick), func(f workflow.Future) {
 			logger.Info("⏰ Tick", zap.Int("players", len(state.Players)))
@@ -304,24 +419,33 @@ func StartRoomWorkflow(ctx workflow.Context, roomID string) error {
 			handleStartGame(baseCtx, state, roomID, logger)
 		}
 
-		// Always send state updates
-		activityCtx := workflow.WithActivityOptions(baseCtx, workflow.ActivityOptions{
-			StartToCloseTimeout: 5 * time.Second,
-			RetryPolicy: &temporal.RetryPolicy{
-				InitialInterval:    time.Second,
-				BackoffCoefficient: 2.0,
-				MaximumAttempts:    3,
-			},
-		})
+		// Таймер хода перезапускается при каждой смене хода
+		turnClock.sync(baseCtx, state)
 
-		input := GameStateActivityInput{
-			RoomID:  state.RoomID,
-			Players: state.Players,
-			State:   *state,
-		}
-		_ = workflow.ExecuteActivity(activityCtx, SendGameStateActivity, input).Get(activityCtx, nil)
+		// Always send state updates
+		sendGameState(baseCtx, state)
 	}
 
 	terminateGame(baseCtx, state, logger)
 	return nil
 }
+
+// sendGameState рассылает состояние стола всем подключённым игрокам
+func sendGameState(ctx workflow.Context, state *RoomState) {
+	activityCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 5 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumAttempts:    3,
+		},
+	})
+
+	input := GameStateActivityInput{
+		RoomID:      state.RoomID,
+		Players:     state.Players,
+		View:        gameStateView(ctx, state),
+		PlayerCards: state.Hand.PlayerCards,
+	}
+	_ = workflow.ExecuteActivity(activityCtx, SendGameStateActivity, input).Get(activityCtx, nil)
+}
@@ -8,94 +8,101 @@ import (
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 	"go.uber.org/zap"
-	"math/rand"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/evaluator"
 	"poker/internal/modules/room/manager"
 	"poker/internal/modules/room/repo"
+	"poker/internal/modules/room/variant"
 	"poker/packages/database"
-	"sort"
+	"strings"
 	"time"
 )
 
 func AllOthersAllInOrFolded(state *RoomState) bool {
 	active := 0
-	for _, id := range state.PlayerOrder {
-		if !state.PlayerFolded[id] && !state.PlayerAllIn[id] {
+	for _, id := range state.Hand.PlayerOrder {
+		if !state.Hand.PlayerFolded[id] && !state.Hand.PlayerAllIn[id] {
 			active++
 		}
 	}
 	return active == 0
 }
 
-func GenerateShuffledDeck(ctx workflow.Context) []string {
-	suits := []string{"♠", "♥", "♦", "♣"}
-	values := []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}
+// NextStage переводит раздачу на следующую улицу разновидности; после последней — вскрытие
+func NextStage(state *RoomState) {
+	if state.Hand.RoundStage == "showdown" {
+		state.Hand.RoundStage = "ended"
+		return
+	}
+	state.Hand.RoundStage = variant.Get(state.Game).NextStreet(state.Hand.RoundStage)
+}
 
-	var deck []string
-	for _, suit := range suits {
-		for _, value := range values {
-			deck = append(deck, value+suit)
-		}
+// dealStreet сдаёт карты текущей улицы: закрытые и открытые — каждому, кто в раздаче, общие — на стол.
+// Возвращает отправку закрытых карт игрокам
+func dealStreet(ctx workflow.Context, state *RoomState) []workflow.Future {
+	street, ok := variant.Get(state.Game).Street(state.Hand.RoundStage)
+	if !ok {
+		return nil
 	}
 
-	var shuffled []string
-	_ = workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
-		shuffledCopy := append([]string(nil), deck...)
-		for i := range shuffledCopy {
-			j := r.Intn(i + 1)
-			shuffledCopy[i], shuffledCopy[j] = shuffledCopy[j], shuffledCopy[i]
+	var active []string
+	for _, id := range state.Hand.PlayerOrder {
+		if !state.Hand.PlayerFolded[id] {
+			active = append(active, id)
 		}
-		return shuffledCopy
-	}).Get(&shuffled)
-
-	return shuffled
-}
+	}
 
-func NextStage(state *RoomState) {
-	switch state.RoundStage {
-	case "preflop":
-		state.RoundStage = "flop"
-	case "flop":
-		state.RoundStage = "turn"
-	case "turn":
-		state.RoundStage = "river"
-	case "river":
-		state.RoundStage = "showdown"
-	default:
-		state.RoundStage = "ended"
+	// В стаде на последней улице карт может не хватить всем — тогда одна карта идёт на стол общей
+	if perPlayer := street.Down + street.Up; perPlayer > 0 && len(state.Hand.Deck) < perPlayer*len(active) {
+		community := state.Hand.Deck.Draw(1)
+		state.Hand.BoardCards = append(state.Hand.BoardCards, community...)
+		sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🃏 Not enough cards — community card: %s", cards.Join(community, ", ")))
+		return nil
 	}
-}
 
-func DealBoardCards(state *RoomState) {
-	switch state.RoundStage {
-	case "flop":
-		if len(state.Deck) >= 3 {
-			state.BoardCards = append(state.BoardCards, state.Deck[0:3]...)
-			state.Deck = state.Deck[3:]
+	var futures []workflow.Future
+	for _, id := range active {
+		down := state.Hand.Deck.Draw(street.Down)
+		up := state.Hand.Deck.Draw(street.Up)
+		state.Hand.PlayerCards[id] = append(state.Hand.PlayerCards[id], down...)
+		state.Hand.PlayerCards[id] = append(state.Hand.PlayerCards[id], up...)
+		if len(up) > 0 {
+			state.Hand.UpCards[id] = append(state.Hand.UpCards[id], up...)
+			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🃏 %s shows %s", id, cards.Join(up, ", ")))
 		}
-	case "turn", "river":
-		if len(state.Deck) >= 1 {
-			state.BoardCards = append(state.BoardCards, state.Deck[0])
-			state.Deck = state.Deck[1:]
+		if len(down) > 0 {
+			state.Hand.MoveLog = append(state.Hand.MoveLog, fmt.Sprintf("deal-card-user-%s", id))
+			msg := fmt.Sprintf("🎴 Your cards: %s", cards.Join(down, ", "))
+			futures = append(futures, workflow.ExecuteActivity(ctx, SendMessageActivity, state.RoomID, id, msg))
 		}
 	}
+
+	state.Hand.BoardCards = append(state.Hand.BoardCards, state.Hand.Deck.Draw(street.Board)...)
+	return futures
 }
 
+// IsBettingRoundOver — все, кто может ставить, уравняли ставку и походили
+// (если ставить может только один игрок, ему достаточно уравнять ставку)
 func IsBettingRoundOver(state *RoomState) bool {
-	activePlayers := 0
-	for _, id := range state.PlayerOrder {
-		if state.PlayerFolded[id] || state.PlayerAllIn[id] {
-			continue
+	var active []string
+	for _, id := range state.Hand.PlayerOrder {
+		if canAct(state, id) {
+			active = append(active, id)
+		}
+	}
+	for _, id := range active {
+		if state.Hand.PlayerBets[id] < state.Hand.CurrentBet {
+			return false
 		}
-		activePlayers++
-		if !state.HasActed[id] {
+		if len(active) > 1 && !state.Hand.HasActed[id] {
 			return false
 		}
 	}
 	return true
 }
 
-func EvaluateWinner(state *RoomState) ([]string, HandScore) {
+// EvaluateWinner возвращает лучших из candidates (несколько — при ничьей)
+func EvaluateWinner(state *RoomState, candidates []string) ([]string, HandScore) {
 	type playerResult struct {
 		ID    string
 		Score HandScore
@@ -103,14 +110,11 @@ func EvaluateWinner(state *RoomState) ([]string, HandScore) {
 
 	var results []playerResult
 
-	for _, playerID := range state.PlayerOrder {
-		if state.PlayerFolded[playerID] {
+	for _, playerID := range candidates {
+		if state.Hand.PlayerFolded[playerID] {
 			continue
 		}
-		cards := append([]string{}, state.PlayerCards[playerID]...)
-		cards = append(cards, state.BoardCards...)
-
-		score := EvaluateHand(cards)
+		score := EvaluateHand(state.Game, state.Hand.PlayerCards[playerID], state.Hand.BoardCards)
 		results = append(results, playerResult{ID: playerID, Score: score})
 	}
 
@@ -123,6 +127,9 @@ func EvaluateWinner(state *RoomState) ([]string, HandScore) {
 
 	for i := 1; i < len(results); i++ {
 		comp := compareHands(results[i].Score, best.Score)
+		if variant.Get(state.Game).Lowball {
+			comp = -comp
+		}
 		if comp > 0 {
 			best = results[i]
 			winners = []string{best.ID}
@@ -134,41 +141,96 @@ func EvaluateWinner(state *RoomState) ([]string, HandScore) {
 	return winners, best.Score
 }
 
-// compareHands возвращает:
-//
-//	1 если h1 > h2
-//	0 если h1 == h2
-//
-// -1 если h1 < h2
-func compareHands(h1, h2 HandScore) int {
-	if h1.Rank > h2.Rank {
-		return 1
-	}
-	if h1.Rank < h2.Rank {
-		return -1
-	}
+// resolveShowdown разыгрывает каждый банк отдельно среди тех, кто может его выиграть.
+// При ничьей банк делится поровну. В хай-лоу каждый банк делится пополам между лучшим хаем
+// и лучшим лоу; если лоу ни у кого нет, хай забирает весь банк
+func resolveShowdown(ctx workflow.Context, state *RoomState) []PotResult {
+	state.Hand.Pots = currentPots(state)
+	takeRake(state)
+	rules := variant.Get(state.Game)
+
+	results := make([]PotResult, 0, len(state.Hand.Pots))
+	for i, pot := range state.Hand.Pots {
+		hiAmount := pot.Amount - pot.Rake
+		var low []WinnerShare
+		if rules.HiLo() {
+			if lowWinners, lowValue := EvaluateLowWinner(state, rules, pot.Eligible); len(lowWinners) > 0 {
+				// Лишняя фишка нечётного банка достаётся хай-половине
+				loAmount := hiAmount / 2
+				hiAmount -= loAmount
+				low = SplitPot(loAmount, lowWinners, state.Hand.PlayerOrder, state.Dealer)
+				for j := range low {
+					low[j].Hand = lowValue.String()
+					low[j].Side = "lo"
+				}
+			}
+		}
 
-	getRanks := func(cards []string) []int {
-		ranks := make([]int, 0, len(cards))
-		for _, card := range cards {
-			ranks = append(ranks, rankMap[extractRank(card)])
+		winners, score := EvaluateWinner(state, pot.Eligible)
+		shares := SplitPot(hiAmount, winners, state.Hand.PlayerOrder, state.Dealer)
+		if len(shares) == 0 {
+			continue
 		}
-		sort.Sort(sort.Reverse(sort.IntSlice(ranks)))
-		return ranks
-	}
+		for j := range shares {
+			shares[j].Hand = score.Desc
+			if rules.HiLo() {
+				shares[j].Side = "hi"
+			}
+		}
+		shares = append(shares, low...)
 
-	r1 := getRanks(h1.Cards)
-	r2 := getRanks(h2.Cards)
+		result := PotResult{Amount: pot.Amount, Rake: pot.Rake, Winners: shares}
+		// Скуп — один игрок забрал спорный банк целиком
+		result.Scoop = rules.HiLo() && len(pot.Eligible) > 1 && len(totalPayouts([]PotResult{result})) == 1
+		for _, w := range shares {
+			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🏆 %s wins %d from %s with %s", w.UserID, w.Amount, potName(i), w.Hand))
+		}
+		if result.Scoop {
+			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🧹 %s scoops %s", shares[0].UserID, potName(i)))
+		}
+		results = append(results, result)
+	}
+	return results
+}
 
-	for i := 0; i < len(r1) && i < len(r2); i++ {
-		if r1[i] > r2[i] {
-			return 1
-		} else if r1[i] < r2[i] {
-			return -1
+// EvaluateLowWinner возвращает лучших по лоу из candidates; пусто, если лоу ни у кого нет
+func EvaluateLowWinner(state *RoomState, rules variant.Variant, candidates []string) ([]string, evaluator.LowValue) {
+	var (
+		best    evaluator.LowValue
+		winners []string
+	)
+	for _, playerID := range candidates {
+		if state.Hand.PlayerFolded[playerID] {
+			continue
+		}
+		low := rules.EvaluateLow(state.Hand.PlayerCards[playerID], state.Hand.BoardCards)
+		switch {
+		case !low.Qualified():
+		case low.Better(best):
+			best = low
+			winners = []string{playerID}
+		case low == best:
+			winners = append(winners, playerID)
 		}
 	}
+	return winners, best
+}
 
-	return 0 // абсолютная ничья
+func potName(i int) string {
+	if i == 0 {
+		return "main pot"
+	}
+	return fmt.Sprintf("side pot #%d", i)
+}
+
+// compareHands возвращает:
+//
+//	1 если h1 > h2
+//	0 если h1 == h2
+//
+// -1 если h1 < h2
+func compareHands(h1, h2 HandScore) int {
+	return evaluator.Compare(h1.Value, h2.Value)
 }
 
 func sendToPlayer(ctx workflow.Context, roomID, userID, message string) {
@@ -188,35 +250,20 @@ func sendToPlayer(ctx workflow.Context, roomID, userID, message string) {
 	}
 }
 
+// dealCards тасует колоду и сдаёт первую улицу раздачи
 func dealCards(ctx workflow.Context, state *RoomState, roomID string) []workflow.Future {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 5 * time.Second,
 	}
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
-	state.Deck = GenerateShuffledDeck(ctx)
-	state.PlayerCards = make(map[string][]string)
-
-	var futures []workflow.Future
-
-	for _, playerID := range state.PlayerOrder {
-		if len(state.Deck) < 2 {
-			break
-		}
-
-		cards := []string{state.Deck[0], state.Deck[1]}
-		state.PlayerCards[playerID] = cards
-		state.Deck = state.Deck[2:]
-
-		eventName := fmt.Sprintf("deal-card-user-%s", playerID)
-		state.MoveLog = append(state.MoveLog, eventName)
+	state.Hand.Deck = shuffleDeck(ctx, state)
+	state.Hand.PlayerCards = make(map[string][]cards.Card)
+	state.Hand.UpCards = make(map[string][]cards.Card)
 
-		msg := fmt.Sprintf("🎴 Your cards: %s, %s", cards[0], cards[1])
-		f := workflow.ExecuteActivity(ctx, SendMessageActivity, roomID, playerID, msg)
-		futures = append(futures, f)
-	}
+	futures := dealStreet(ctx, state)
 
-	sendToAllPlayers(ctx, state.RoomID, state.Players, "🃏 Cards have been dealt")
+	sendToAllPlayers(ctx, roomID, state.Players, "🃏 Cards have been dealt")
 	return futures
 }
 
@@ -243,33 +290,96 @@ func (m *multiFuture) IsReady() bool {
 	return true
 }
 
+// GameStateView — стол, каким его рисует клиент. В активность уходит только он,
+// а не всё состояние воркфлоу с колодой, сидами и историей раздачи
+type GameStateView struct {
+	Players          map[string]bool          `json:"players"`
+	Pot              int64                    `json:"pot"`
+	CommunityCards   []cards.Card             `json:"communityCards"`
+	Pots             []SidePot                `json:"pots"`
+	RoomID           string                   `json:"roomId"`
+	HandNumber       int                      `json:"handNumber"`
+	Status           string                   `json:"status"`
+	CurrentTurn      string                   `json:"currentTurn"`
+	Dealer           string                   `json:"dealer"`
+	SmallBlind       string                   `json:"smallBlind"`
+	BigBlind         string                   `json:"bigBlind"`
+	Blinds           map[string]int64         `json:"blinds"`
+	BuyIn            map[string]int64         `json:"buyIn"`
+	Rake             map[string]int64         `json:"rake"`
+	Stacks           map[string]int64         `json:"stacks"` // фишки на столе, не баланс кошелька
+	GameType         variant.GameType         `json:"gameType"`
+	BettingStructure variant.BettingStructure `json:"bettingStructure"`
+	WinnerID         string                   `json:"winnerId"`
+	Clock            map[string]interface{}   `json:"clock"`
+	PlayerCards      map[string][]cards.Card  `json:"playerCards"` // только карты получателя
+	// Открытые карты стада видны всем, закрытые — только в playerCards владельца
+	UpCards  map[string][]cards.Card `json:"upCards"`
+	BringIn  string                  `json:"bringIn"`
+	Drawing  bool                    `json:"drawing"`
+	Rotation map[string]interface{}  `json:"rotation"`
+	Fairness map[string]interface{}  `json:"fairness"`
+}
+
 type GameStateActivityInput struct {
-	RoomID  string
-	Players map[string]bool
-	State   RoomState
+	RoomID      string
+	Players     map[string]bool
+	View        GameStateView
+	PlayerCards map[string][]cards.Card // закрытые карты; каждому игроку уходят только его
+}
+
+// gameStateView собирает для клиентов текущий вид стола
+func gameStateView(ctx workflow.Context, state *RoomState) GameStateView {
+	status := "waiting"
+	if state.GameStarted {
+		status = "playing"
+	}
+	return GameStateView{
+		Players:        state.Players,
+		Pot:            state.Hand.Pot,
+		CommunityCards: state.Hand.BoardCards,
+		Pots:           BuildPots(state.Hand.PlayerOrder, state.Hand.Contributions, state.Hand.PlayerFolded),
+		RoomID:         state.RoomID,
+		HandNumber:     state.HandNumber,
+		Status:         status,
+		CurrentTurn:    state.Hand.CurrentPlayer,
+		Dealer:         state.Dealer,
+		SmallBlind:     state.Hand.SmallBlindPlayer,
+		BigBlind:       state.Hand.BigBlindPlayer,
+		Blinds: map[string]int64{
+			"small": state.Blinds.SmallBlind,
+			"big":   state.Blinds.BigBlind,
+			"ante":  state.Blinds.Ante,
+		},
+		BuyIn: map[string]int64{
+			"min": state.BuyIn.Min,
+			"max": state.BuyIn.Max,
+		},
+		Rake: map[string]int64{
+			"basisPoints": state.Rake.BasisPoints,
+			"cap":         state.Rake.Cap,
+		},
+		Stacks:           state.PlayerChips,
+		GameType:         state.Game,
+		BettingStructure: state.Betting,
+		Clock:            clockPayload(*state, workflow.Now(ctx)),
+		UpCards:          state.Hand.UpCards,
+		BringIn:          state.Hand.BringInPlayer,
+		Drawing:          state.Hand.Drawing,
+		Rotation:         rotationPayload(*state),
+		Fairness:         fairnessPayload(*state),
+	}
 }
 
 func SendGameStateActivity(ctx context.Context, input GameStateActivityInput) error {
 	for userID := range input.Players {
+		view := input.View
+		view.PlayerCards = map[string][]cards.Card{
+			userID: input.PlayerCards[userID],
+		}
 		personalPayload := map[string]interface{}{
-			"type": "update-game-state",
-			"payload": map[string]interface{}{
-				"players":        input.State.Players,
-				"pot":            input.State.Pot,
-				"communityCards": input.State.BoardCards,
-				"roomId":         input.State.RoomID,
-				"status": func() string {
-					if input.State.GameStarted {
-						return "playing"
-					}
-					return "waiting"
-				}(),
-				"currentTurn": input.State.CurrentPlayer,
-				"winnerId":    "",
-				"playerCards": map[string][]string{
-					userID: input.State.PlayerCards[userID],
-				},
-			},
+			"type":    "update-game-state",
+			"payload": view,
 		}
 
 		jsonData, err := json.Marshal(personalPayload)
@@ -294,81 +404,76 @@ func SendMessage(roomID, userID, message string) error {
 	return conn.WriteMessage(1, []byte(message))
 }
 
+// handleStartGame запускает сессию: раздачи сдаются одна за другой,
+// пока за столом есть хотя бы два игрока с фишками
 func handleStartGame(ctx workflow.Context, state *RoomState, roomID string, logger log.Logger) {
-	if len(state.Players) == 0 {
-		logger.Warn("Cannot start game: no players in room")
-		sendToAllPlayers(ctx, roomID, state.Players, "⚠️ Game cannot start, no players in room")
+	if state.GameStarted {
+		logger.Warn("Game already started")
+		return
+	}
+	if len(state.Players) < 2 {
+		logger.Warn("Cannot start game: not enough players in room")
+		sendToAllPlayers(ctx, roomID, state.Players, "⚠️ Game cannot start, need at least 2 players")
 		return
 	}
 
 	state.GameStarted = true
 	rr := repo.NewRoomRepo(database.DB)
 	_ = rr.UpdateRoomStatus(state.RoomID, "Started")
-	state.PlayerOrder = make([]string, 0)
-	state.PlayerChips = make(map[string]int64)
-	state.PlayerFolded = make(map[string]bool)
-	state.PlayerAllIn = make(map[string]bool)
-	state.RoundStage = "preflop"
-	state.HasActed = make(map[string]bool)
-	state.PlayerBets = make(map[string]int64)
-
-	ao := workflow.ActivityOptions{
-		StartToCloseTimeout: 5 * time.Second,
-	}
-	actCtx := workflow.WithActivityOptions(ctx, ao)
 
-	for id := range state.Players {
-		state.PlayerOrder = append(state.PlayerOrder, id)
-
-		var balance int64
-		err := workflow.ExecuteActivity(actCtx, GetPlayerBalanceActivity, id).Get(actCtx, &balance)
-		if err != nil {
-			logger.Error("❌ Failed to get player balance", zap.String("userID", id), zap.Error(err))
-			balance = 0
-		}
-
-		state.PlayerChips[id] = balance
-		state.PlayerFolded[id] = false
-		state.PlayerAllIn[id] = false
-	}
-
-	if len(state.PlayerOrder) == 0 {
-		logger.Error("PlayerOrder is still empty after init")
-		return
-	}
+	sendToAllPlayers(ctx, roomID, state.Players, "🎮 Game started!")
+	startHand(ctx, state)
+}
 
-	state.CurrentPlayer = state.PlayerOrder[0]
+// WinnerView — итог раздачи для клиентов
+type WinnerView struct {
+	Players        []string                `json:"players"`
+	Pot            int64                   `json:"pot"`
+	Pots           []SidePot               `json:"pots"`
+	Results        []PotResult             `json:"results"`
+	CommunityCards []cards.Card            `json:"communityCards"`
+	RoomID         string                  `json:"roomId"`
+	Status         string                  `json:"status"`
+	CurrentTurn    string                  `json:"currentTurn"`
+	WinnerID       string                  `json:"winnerId"`
+	Winners        []WinnerShare           `json:"winners"`
+	Rake           int64                   `json:"rake"`
+	PlayerCards    map[string][]cards.Card `json:"playerCards"` // только карты получателя
+	Fairness       map[string]interface{}  `json:"fairness"`
+}
 
-	logger.Info("🎮 Game started", zap.String("firstPlayer", state.CurrentPlayer))
-	sendToAllPlayers(ctx, roomID, state.Players, "🎮 Game started!")
+type WinnerPayloadInput struct {
+	RoomID      string
+	Players     map[string]bool
+	View        WinnerView
+	PlayerCards map[string][]cards.Card
+}
 
-	futures := dealCards(ctx, state, roomID)
-	for _, f := range futures {
-		if err := f.Get(ctx, nil); err != nil {
-			logger.Error("Failed to deal cards", zap.Error(err))
-		}
+func winnerView(state *RoomState, winnerID string) WinnerView {
+	return WinnerView{
+		Players:        state.Hand.PlayerOrder,
+		Pot:            state.Hand.Pot,
+		Pots:           state.Hand.Pots,
+		Results:        state.Hand.Results,
+		CommunityCards: state.Hand.BoardCards,
+		RoomID:         state.RoomID,
+		Status:         "ended",
+		WinnerID:       winnerID,
+		Winners:        state.Hand.Winners,
+		Rake:           state.Hand.Rake,
+		Fairness:       fairnessPayload(*state),
 	}
-
-	sendToAllPlayers(ctx, roomID, state.Players, fmt.Sprintf("🕓 First turn: %s", state.CurrentPlayer))
-	sendToPlayer(ctx, roomID, state.CurrentPlayer, "🟢 Your turn")
 }
 
-func SendWinnerPayloadActivity(ctx context.Context, roomID, winnerID string, players map[string]bool, state RoomState) error {
-	for userID := range players {
+func SendWinnerPayloadActivity(ctx context.Context, input WinnerPayloadInput) error {
+	for userID := range input.Players {
+		view := input.View
+		view.PlayerCards = map[string][]cards.Card{
+			userID: input.PlayerCards[userID],
+		}
 		payload := map[string]interface{}{
-			"type": "update-game-state",
-			"payload": map[string]interface{}{
-				"players":        state.PlayerOrder,
-				"pot":            state.Pot,
-				"communityCards": state.BoardCards,
-				"roomId":         roomID,
-				"status":         "ended",
-				"currentTurn":    "",
-				"winnerId":       winnerID,
-				"playerCards": map[string][]string{
-					userID: state.PlayerCards[userID],
-				},
-			},
+			"type":    "update-game-state",
+			"payload": view,
 		}
 
 		jsonData, err := json.Marshal(payload)
@@ -376,7 +481,7 @@ func SendWinnerPayloadActivity(ctx context.Context, roomID, winnerID string, pla
 			return err
 		}
 
-		if err := SendMessage(roomID, userID, string(jsonData)); err != nil {
+		if err := SendMessage(input.RoomID, userID, string(jsonData)); err != nil {
 			return err
 		}
 	}
@@ -389,10 +494,13 @@ func terminateGame(ctx workflow.Context, state *RoomState, logger log.Logger) {
 	ao := workflow.ActivityOptions{StartToCloseTimeout: 10 * time.Second}
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
-	if err := workflow.ExecuteActivity(ctx, SaveGameHistoryActivity, state).Get(ctx, nil); err != nil {
+	if err := workflow.ExecuteActivity(ctx, SaveGameHistoryActivity, handRecord(state)).Get(ctx, nil); err != nil {
 		logger.Error("❌ Failed to save history", "err", err)
 	}
 
+	// Стол закрывается — все стеки возвращаются в кошельки
+	cashOutAll(ctx, state)
+
 	if len(state.Players) > 0 {
 		err := workflow.ExecuteActivity(ctx, DisconnectAllUsersActivity, state.RoomID).Get(ctx, nil)
 		if err != nil {
@@ -410,135 +518,96 @@ func terminateGame(ctx workflow.Context, state *RoomState, logger log.Logger) {
 	logger.Info("🏁 Game ended. Terminating workflow...")
 }
 
+// NextTurn передаёт ход следующему игроку, а если круг ставок закончен —
+// переходит на следующую улицу или к вскрытию
 func NextTurn(ctx workflow.Context, state *RoomState) {
-	n := len(state.PlayerOrder)
-	if n == 0 {
+	if len(state.Hand.PlayerOrder) == 0 {
 		return
 	}
 
-	currentIdx := -1
-	for i, id := range state.PlayerOrder {
-		if id == state.CurrentPlayer {
-			currentIdx = i
-			break
-		}
-	}
-
-	notFolded := 0
-	canAct := []string{}
-	for _, id := range state.PlayerOrder {
-		if !state.PlayerFolded[id] {
-			notFolded++
-			if !state.PlayerAllIn[id] {
-				canAct = append(canAct, id)
-			}
+	var notFolded []string
+	for _, id := range state.Hand.PlayerOrder {
+		if !state.Hand.PlayerFolded[id] {
+			notFolded = append(notFolded, id)
 		}
 	}
 
 	// Победа по фолду всех
-	if notFolded == 1 {
-		for _, id := range state.PlayerOrder {
-			if !state.PlayerFolded[id] {
-				announceWinner(ctx, state, id, workflow.GetLogger(ctx))
-
-				state.RoundStage = "ended"
-				state.GameStarted = false
-				state.CurrentPlayer = ""
-
-				ao := workflow.ActivityOptions{
-					StartToCloseTimeout: 5 * time.Second,
-				}
-				actCtx := workflow.WithActivityOptions(ctx, ao)
-
-				_ = workflow.ExecuteActivity(actCtx, SendWinnerPayloadActivity, state.RoomID, id, state.Players, *state).Get(actCtx, nil)
-
-				_ = workflow.ExecuteActivity(actCtx, CreditWinningsActivity, BalanceUpdateInput{
-					UserID: id,
-					Amount: state.Pot,
-				}).Get(actCtx, nil)
-
-				_ = workflow.ExecuteActivity(actCtx, UpdateUserElo, id, int64(10), true) // победителю +10
-				for otherID := range state.Players {
-					if otherID != id && !state.PlayerFolded[otherID] {
-						_ = workflow.ExecuteActivity(actCtx, UpdateUserElo, otherID, int64(-10), false) // проигравшим -10
-					}
-				}
+	if len(notFolded) == 1 {
+		state.Hand.Pots = currentPots(state)
+		takeRake(state)
+		finishHand(ctx, state, []PotResult{{
+			Amount:  state.Hand.Pot,
+			Rake:    state.Hand.Rake,
+			Winners: []WinnerShare{{UserID: notFolded[0], Amount: state.Hand.Pot - state.Hand.Rake}},
+		}})
+		return
+	}
 
-				terminateGame(ctx, state, workflow.GetLogger(ctx))
-				state.Terminated = true
-				return
-			}
+	// Обмен: карты меняют по очереди, затем начинается торговля
+	if state.Hand.Drawing {
+		if next := nextDrawer(state, state.Hand.CurrentPlayer); next != "" {
+			state.Hand.CurrentPlayer = next
+			sendToPlayer(ctx, state.RoomID, next, "🔁 Your draw")
+			return
+		}
+		state.Hand.Drawing = false
+		startBettingRound(state)
+		if IsBettingRoundOver(state) {
+			NextTurn(ctx, state)
+			return
 		}
+		sendToPlayer(ctx, state.RoomID, state.Hand.CurrentPlayer, "🟢 Your turn")
+		return
 	}
 
-	state.CurrentPlayer = ""
-
-	if len(canAct) == 0 {
-		if state.RoundStage == "river" || state.RoundStage == "showdown" {
-			winners, _ := EvaluateWinner(state)
-			winner := winners[0]
-
-			announceWinner(ctx, state, winner, workflow.GetLogger(ctx))
-			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🏆 %s wins with %s", winner))
+	if IsBettingRoundOver(state) {
+		if state.Hand.RoundStage == variant.Get(state.Game).LastStreet() || state.Hand.RoundStage == "showdown" {
+			state.Hand.RoundStage = "showdown"
+			finishHand(ctx, state, resolveShowdown(ctx, state))
+			return
+		}
 
-			ao := workflow.ActivityOptions{
-				StartToCloseTimeout: 5 * time.Second,
-			}
-			actCtx := workflow.WithActivityOptions(ctx, ao)
-
-			_ = workflow.ExecuteActivity(
-				actCtx,
-				SendWinnerPayloadActivity,
-				state.RoomID,
-				winner,
-				state.Players,
-				*state,
-			).Get(actCtx, nil)
-
-			_ = workflow.ExecuteActivity(actCtx, CreditWinningsActivity, BalanceUpdateInput{
-				UserID: winner,
-				Amount: state.Pot,
-			}).Get(actCtx, nil)
-
-			// 🔄 Обновление ELO
-			_ = workflow.ExecuteActivity(actCtx, UpdateUserElo, winner, int64(10), true)
-			for _, id := range state.PlayerOrder {
-				if id != winner && !state.PlayerFolded[id] {
-					_ = workflow.ExecuteActivity(actCtx, UpdateUserElo, id, int64(-10), false)
-				}
+		NextStage(state)
+		dealCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: 5 * time.Second})
+		for _, f := range dealStreet(dealCtx, state) {
+			if err := f.Get(ctx, nil); err != nil {
+				workflow.GetLogger(ctx).Error("Failed to deal cards", zap.Error(err))
 			}
+		}
+		sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🃏 New stage: %s", state.Hand.RoundStage))
 
-			state.RoundStage = "ended"
-			state.CurrentPlayer = ""
-			state.GameStarted = false
-			state.Terminated = true
-			terminateGame(ctx, state, workflow.GetLogger(ctx))
+		if street, _ := variant.Get(state.Game).Street(state.Hand.RoundStage); street.Draw {
+			beginDraw(ctx, state)
+			sendToPlayer(ctx, state.RoomID, state.Hand.CurrentPlayer, "🔁 Your draw")
 			return
-		} else {
-			NextStage(state)
-			DealBoardCards(state)
-			sendToAllPlayers(ctx, state.RoomID, state.Players, fmt.Sprintf("🃏 New stage: %s", state.RoundStage))
+		}
+		startBettingRound(state)
+
+		// Ставить больше некому — докладываем борд до конца
+		if IsBettingRoundOver(state) {
 			NextTurn(ctx, state)
 			return
 		}
+		sendToPlayer(ctx, state.RoomID, state.Hand.CurrentPlayer, "🟢 Your turn")
+		return
 	}
 
 	// Передача хода
-	for i := 1; i <= n; i++ {
-		nextIdx := (currentIdx + i) % n
-		next := state.PlayerOrder[nextIdx]
-		if !state.PlayerFolded[next] && !state.PlayerAllIn[next] {
-			state.CurrentPlayer = next
-			sendToPlayer(ctx, state.RoomID, state.CurrentPlayer, "🟢 Your turn")
-			return
-		}
+	state.Hand.CurrentPlayer = nextPlayer(state.Hand.PlayerOrder, state.Hand.CurrentPlayer, func(id string) bool {
+		return canAct(state, id)
+	})
+	if state.Hand.CurrentPlayer != "" {
+		sendToPlayer(ctx, state.RoomID, state.Hand.CurrentPlayer, "🟢 Your turn")
 	}
-
-	state.CurrentPlayer = ""
 }
 
-func announceWinner(ctx workflow.Context, state *RoomState, winnerID string, logger log.Logger) {
-	message := fmt.Sprintf("Победитель: %s!", winnerID)
+func announceWinner(ctx workflow.Context, state *RoomState, winners []WinnerShare, logger log.Logger) {
+	names := make([]string, 0, len(winners))
+	for _, w := range winners {
+		names = append(names, w.UserID)
+	}
+	message := fmt.Sprintf("Победитель: %s!", strings.Join(names, ", "))
 
 	sendToAllPlayers(ctx, state.RoomID, state.Players, message)
 
@@ -549,9 +618,10 @@ func announceWinner(ctx workflow.Context, state *RoomState, winnerID string, log
 
 	err := workflow.ExecuteActivity(actCtx, SendWinnerAnnouncementActivity, SendWinnerInput{
 		RoomID:   state.RoomID,
-		WinnerID: winnerID,
+		WinnerID: winners[0].UserID,
 		Message:  message,
-		Amount:   state.Pot,
+		Amount:   winners[0].Amount,
+		Winners:  winners,
 	}).Get(actCtx, nil)
 
 	if err != nil {
@@ -559,12 +629,6 @@ func announceWinner(ctx workflow.Context, state *RoomState, winnerID string, log
 	}
 }
 
-func updateUserBalance(userID string, delta int64) error {
-
-	fmt.Printf("🔄 Updating balance for user %s by %+d\n", userID, delta)
-	return nil
-}
-
 func sendToPlayerWithRetries(ctx workflow.Context, roomID, userID, message string, maxAttempts int) bool {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 3 * time.Second,
@@ -18,15 +18,15 @@ func (a FoldAction) Validate(state *RoomState, userID string, args []string) err
 	return nil
 }
 func (a FoldAction) Execute(state *RoomState, userID string, args []string) {
-	state.PlayerFolded[userID] = true
+	state.Hand.PlayerFolded[userID] = true
 }
 
 // ======================= CHECK =======================
 type CheckAction struct{}
 
 func (a CheckAction) Validate(state *RoomState, userID string, args []string) error {
-	if state.CurrentBet > 0 {
-		return fmt.Errorf("cannot check, current bet is %d", state.CurrentBet)
+	if toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]; toCall > 0 {
+		return fmt.Errorf("cannot check, %d to call", toCall)
 	}
 	return nil
 }
@@ -38,7 +38,7 @@ func (a CheckAction) Execute(state *RoomState, userID string, args []string) {
 type CallAction struct{}
 
 func (a CallAction) Validate(state *RoomState, userID string, args []string) error {
-	toCall := state.CurrentBet - state.PlayerBets[userID]
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
 	if toCall <= 0 {
 		return fmt.Errorf("nothing to call")
 	}
@@ -48,17 +48,15 @@ func (a CallAction) Validate(state *RoomState, userID string, args []string) err
 	return nil
 }
 func (a CallAction) Execute(state *RoomState, userID string, args []string) {
-	toCall := state.CurrentBet - state.PlayerBets[userID]
-	state.PlayerChips[userID] -= toCall
-	state.PlayerBets[userID] += toCall
-	state.Pot += toCall
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
+	commitChips(state, userID, toCall, true)
 }
 
 // ======================= BET =======================
 type BetAction struct{}
 
 func (a BetAction) Validate(state *RoomState, userID string, args []string) error {
-	if state.CurrentBet > 0 {
+	if state.Hand.CurrentBet > 0 {
 		return fmt.Errorf("cannot bet, current bet already placed")
 	}
 	if len(args) != 1 {
@@ -71,23 +69,22 @@ func (a BetAction) Validate(state *RoomState, userID string, args []string) erro
 	if int64(amount) > state.PlayerChips[userID] {
 		return fmt.Errorf("not enough chips to bet")
 	}
-	return nil
+	return checkBetSize(state, userID, int64(amount))
 }
 func (a BetAction) Execute(state *RoomState, userID string, args []string) {
 	amount, _ := strconv.Atoi(args[0])
 	amt := int64(amount)
-	state.PlayerChips[userID] -= amt
-	state.PlayerBets[userID] += amt
-	state.Pot += amt
-	state.CurrentBet = amt
-	state.LastRaise = amt
+	commitChips(state, userID, amt, true)
+	state.Hand.CurrentBet = amt
+	state.Hand.LastRaise = amt
+	state.Hand.Raises++
 }
 
 // ======================= RAISE =======================
 type RaiseAction struct{}
 
 func (a RaiseAction) Validate(state *RoomState, userID string, args []string) error {
-	if state.CurrentBet == 0 {
+	if state.Hand.CurrentBet == 0 {
 		return fmt.Errorf("cannot raise, no bet yet")
 	}
 	if len(args) != 1 {
@@ -97,63 +94,64 @@ func (a RaiseAction) Validate(state *RoomState, userID string, args []string) er
 	if err != nil || amount <= 0 {
 		return fmt.Errorf("invalid raise amount")
 	}
-	if int64(amount) < state.LastRaise {
-		return fmt.Errorf("raise must be at least %d", state.LastRaise)
-	}
-	toCall := state.CurrentBet - state.PlayerBets[userID]
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
 	total := toCall + int64(amount)
 	if state.PlayerChips[userID] < total {
 		return fmt.Errorf("not enough chips to raise: need %d, have %d", total, state.PlayerChips[userID])
 	}
-	return nil
+	return checkBetSize(state, userID, int64(amount))
 }
 func (a RaiseAction) Execute(state *RoomState, userID string, args []string) {
 	amount, _ := strconv.Atoi(args[0])
-	toCall := state.CurrentBet - state.PlayerBets[userID]
+	toCall := state.Hand.CurrentBet - state.Hand.PlayerBets[userID]
 	total := toCall + int64(amount)
-	state.PlayerChips[userID] -= total
-	state.PlayerBets[userID] += total
-	state.Pot += total
-	state.LastRaise = int64(amount)
-	state.CurrentBet += int64(amount)
+	commitChips(state, userID, total, true)
+	state.Hand.LastRaise = int64(amount)
+	state.Hand.CurrentBet += int64(amount)
+	state.Hand.Raises++
 }
 
 // ======================= ALLIN =======================
 type AllinAction struct{}
 
+// Олл-ин — всегда весь стек. Сумма необязательна, но если передана, должна быть равна стеку:
+// часть стека ставится через bet или raise
 func (a AllinAction) Validate(state *RoomState, userID string, args []string) error {
 	if len(args) > 1 {
 		return fmt.Errorf("allin takes zero or one argument")
 	}
 	amount := state.PlayerChips[userID]
-
+	if amount <= 0 {
+		return fmt.Errorf("you have no chips to allin")
+	}
 	if len(args) == 1 {
-		a, err := strconv.Atoi(args[0])
+		a, err := strconv.ParseInt(args[0], 10, 64)
 		if err != nil || a <= 0 {
 			return fmt.Errorf("invalid allin amount")
 		}
-		if int64(a) > amount {
-			return fmt.Errorf("you can't allin more than you have")
+		if a != amount {
+			return fmt.Errorf("allin is your whole stack (%d), use bet or raise for less", amount)
 		}
 	}
-	if amount <= 0 {
-		return fmt.Errorf("you have no chips to allin")
+	if !allinAllowed(state, userID, amount) {
+		if !raiseReopened(state, userID) {
+			return fmt.Errorf("short allin did not reopen the betting, call or fold")
+		}
+		return fmt.Errorf("allin exceeds the %s maximum, bet or raise instead", state.Betting)
 	}
 	return nil
 }
 func (a AllinAction) Execute(state *RoomState, userID string, args []string) {
 	amount := state.PlayerChips[userID]
-	if len(args) == 1 {
-		val, _ := strconv.Atoi(args[0])
-		amount = int64(val)
-	}
-	state.PlayerChips[userID] -= amount
-	state.PlayerAllIn[userID] = true
-	state.PlayerBets[userID] += amount
-	state.Pot += amount
-	if state.PlayerBets[userID] > state.CurrentBet {
-		state.LastRaise = state.PlayerBets[userID] - state.CurrentBet
-		state.CurrentBet = state.PlayerBets[userID]
+	commitChips(state, userID, amount, true)
+	state.Hand.PlayerAllIn[userID] = true
+	if state.Hand.PlayerBets[userID] > state.Hand.CurrentBet {
+		// Неполное повышение не меняет минимальный рейз
+		if raise := state.Hand.PlayerBets[userID] - state.Hand.CurrentBet; raise >= state.Hand.LastRaise {
+			state.Hand.LastRaise = raise
+		}
+		state.Hand.CurrentBet = state.Hand.PlayerBets[userID]
+		state.Hand.Raises++
 	}
 }
 
@@ -166,15 +164,19 @@ var ActionRegistry = map[string]PlayerAction{
 	"raise": RaiseAction{},
 	"bet":   BetAction{},
 	"allin": AllinAction{},
+	"draw":  DrawAction{},
 }
 
 func ValidatePlayerAction(action string, state *RoomState, userID string, args []string) error {
-	if userID != state.CurrentPlayer {
+	if userID != state.Hand.CurrentPlayer {
 		return fmt.Errorf("not your turn")
 	}
 	handler, ok := ActionRegistry[action]
 	if !ok {
 		return fmt.Errorf("unknown action: %s", action)
 	}
+	if state.Hand.Drawing && action != "draw" {
+		return fmt.Errorf("draw first")
+	}
 	return handler.Validate(state, userID, args)
 }
@@ -0,0 +1,32 @@
+package variant
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BettingStructure — правила размера ставок за столом
+type BettingStructure string
+
+const (
+	NoLimit    BettingStructure = "no-limit"
+	PotLimit   BettingStructure = "pot-limit"
+	FixedLimit BettingStructure = "fixed-limit"
+
+	DefaultBettingStructure = NoLimit
+)
+
+// ParseBettingStructure проверяет структуру ставок; пустая строка — безлимит
+func ParseBettingStructure(s string) (BettingStructure, error) {
+	switch BettingStructure(strings.ToLower(strings.TrimSpace(s))) {
+	case "":
+		return DefaultBettingStructure, nil
+	case NoLimit:
+		return NoLimit, nil
+	case PotLimit:
+		return PotLimit, nil
+	case FixedLimit:
+		return FixedLimit, nil
+	}
+	return "", fmt.Errorf("invalid betting structure %q: expected %s, %s or %s", s, NoLimit, PotLimit, FixedLimit)
+}
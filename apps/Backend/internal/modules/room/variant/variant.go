@@ -0,0 +1,228 @@
+// Пакет variant — разновидности покера и структуры ставок. От воркфлоу стола не зависит,
+// поэтому его импортируют и экспорт истории, и утилиты
+package variant
+
+import (
+	"fmt"
+	"poker/internal/modules/room/cards"
+	"poker/internal/modules/room/evaluator"
+	"sort"
+	"strings"
+)
+
+// GameType — разновидность покера за столом
+type GameType string
+
+const (
+	Holdem     GameType = "holdem"
+	Omaha      GameType = "omaha"
+	HoldemHiLo GameType = "holdem-hilo"
+	OmahaHiLo  GameType = "omaha-hilo"
+	ShortDeck  GameType = "short-deck"
+	Stud       GameType = "stud"
+	StudHiLo   GameType = "stud-hilo"
+	TripleDraw GameType = "2-7-triple-draw"
+
+	DefaultGameType = Holdem
+)
+
+// Street — улица раздачи и карты, которые на ней сдаются
+type Street struct {
+	Name   string
+	Down   int  // закрытых карт каждому игроку
+	Up     int  // открытых карт каждому игроку (стад)
+	Board  int  // общих карт
+	Draw   bool // перед торговлей игроки меняют карты (дро-игры)
+	BigBet bool // в лимите ставка на этой улице удваивается
+}
+
+// holdemStreets — префлоп, флоп, тёрн и ривер; hole — сколько карт в руке
+func holdemStreets(hole int) []Street {
+	return []Street{
+		{Name: "preflop", Down: hole},
+		{Name: "flop", Board: 3},
+		{Name: "turn", Board: 1, BigBet: true},
+		{Name: "river", Board: 1, BigBet: true},
+	}
+}
+
+// studStreets — с третьей по седьмую улицу: две закрытые и открытая, три открытых, закрытая
+var studStreets = []Street{
+	{Name: "third", Down: 2, Up: 1},
+	{Name: "fourth", Up: 1},
+	{Name: "fifth", Up: 1, BigBet: true},
+	{Name: "sixth", Up: 1, BigBet: true},
+	{Name: "seventh", Down: 1, BigBet: true},
+}
+
+// tripleDrawStreets — пять закрытых карт и три обмена с торговлей после каждого
+var tripleDrawStreets = []Street{
+	{Name: "predraw", Down: 5},
+	{Name: "draw-1", Draw: true},
+	{Name: "draw-2", Draw: true, BigBet: true},
+	{Name: "draw-3", Draw: true, BigBet: true},
+}
+
+// Variant — правила разновидности: какая колода, как сдаются карты и как оценивается рука
+type Variant struct {
+	Type           GameType
+	MinRank        cards.Rank // младший ранг колоды
+	Streets        []Street
+	MaxPlayers     int // 0 — без ограничений сверх размера стола
+	DefaultBetting BettingStructure
+	Evaluate       func(hole, board []cards.Card) evaluator.HandValue
+
+	// Stud — блайндов нет: младшая открытая карта ставит бринг-ин,
+	// на следующих улицах первым ходит лучшая открытая рука
+	Stud bool
+
+	// Lowball — выигрывает младшая рука (2-7): Evaluate считает её как обычную, сравнение обратное
+	Lowball bool
+
+	// EvaluateLow задан у хай-лоу игр: половина банка уходит лучшему лоу «восемь или ниже»
+	EvaluateLow func(hole, board []cards.Card) evaluator.LowValue
+}
+
+func (v Variant) HiLo() bool { return v.EvaluateLow != nil }
+
+var variants = map[GameType]Variant{
+	Holdem: {
+		Type:           Holdem,
+		Streets:        holdemStreets(2),
+		DefaultBetting: NoLimit,
+		Evaluate: func(hole, board []cards.Card) evaluator.HandValue {
+			return evaluator.Evaluate(joinCards(hole, board))
+		},
+	},
+	// Омаха играется пот-лимитом: ровно две карты руки и три карты борда
+	Omaha: {
+		Type:           Omaha,
+		Streets:        holdemStreets(4),
+		DefaultBetting: PotLimit,
+		Evaluate:       evaluator.EvaluateOmaha,
+	},
+	HoldemHiLo: {
+		Type:           HoldemHiLo,
+		Streets:        holdemStreets(2),
+		DefaultBetting: FixedLimit,
+		Evaluate: func(hole, board []cards.Card) evaluator.HandValue {
+			return evaluator.Evaluate(joinCards(hole, board))
+		},
+		EvaluateLow: func(hole, board []cards.Card) evaluator.LowValue {
+			return evaluator.EvaluateLow(joinCards(hole, board))
+		},
+	},
+	OmahaHiLo: {
+		Type:           OmahaHiLo,
+		Streets:        holdemStreets(4),
+		DefaultBetting: PotLimit,
+		Evaluate:       evaluator.EvaluateOmaha,
+		EvaluateLow:    evaluator.EvaluateOmahaLow,
+	},
+	// Шорт-дек: 36 карт с шестёрки, флеш старше фулл-хауса, A-6-7-8-9 — младший стрит
+	ShortDeck: {
+		Type:           ShortDeck,
+		MinRank:        cards.Six,
+		Streets:        holdemStreets(2),
+		DefaultBetting: NoLimit,
+		Evaluate: func(hole, board []cards.Card) evaluator.HandValue {
+			return evaluator.ShortDeck().Evaluate(joinCards(hole, board))
+		},
+	},
+	// Стад: семь карт на игрока, поэтому за столом не больше восьми.
+	// Лимиты стола читаются как бринг-ин/малая ставка/анте
+	Stud: {
+		Type:           Stud,
+		Streets:        studStreets,
+		MaxPlayers:     8,
+		DefaultBetting: FixedLimit,
+		Evaluate: func(hole, board []cards.Card) evaluator.HandValue {
+			return evaluator.Evaluate(joinCards(hole, board))
+		},
+		Stud: true,
+	},
+	// Стад хай-лоу (Stud/8): как стад, но половина банка уходит лучшему лоу из семи карт
+	StudHiLo: {
+		Type:           StudHiLo,
+		Streets:        studStreets,
+		MaxPlayers:     8,
+		DefaultBetting: FixedLimit,
+		Evaluate: func(hole, board []cards.Card) evaluator.HandValue {
+			return evaluator.Evaluate(joinCards(hole, board))
+		},
+		EvaluateLow: func(hole, board []cards.Card) evaluator.LowValue {
+			return evaluator.EvaluateLow(joinCards(hole, board))
+		},
+		Stud: true,
+	},
+	// Лоубол 2-7 тройной обмен: до трёх замен по пять карт, поэтому не больше шести за столом
+	TripleDraw: {
+		Type:           TripleDraw,
+		Streets:        tripleDrawStreets,
+		MaxPlayers:     6,
+		DefaultBetting: FixedLimit,
+		Evaluate: func(hole, board []cards.Card) evaluator.HandValue {
+			return evaluator.EvaluateDeuceToSeven(hole)
+		},
+		Lowball: true,
+	},
+}
+
+// Street возвращает улицу по названию; ok == false, если такой улицы нет
+func (v Variant) Street(name string) (Street, bool) {
+	for _, s := range v.Streets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Street{}, false
+}
+
+// NextStreet — название улицы после name; после последней — вскрытие
+func (v Variant) NextStreet(name string) string {
+	for i, s := range v.Streets {
+		if s.Name == name && i+1 < len(v.Streets) {
+			return v.Streets[i+1].Name
+		}
+	}
+	return "showdown"
+}
+
+// LastStreet — название последней улицы, после неё — вскрытие
+func (v Variant) LastStreet() string {
+	return v.Streets[len(v.Streets)-1].Name
+}
+
+func joinCards(hole, board []cards.Card) []cards.Card {
+	return append(append(make([]cards.Card, 0, len(hole)+len(board)), hole...), board...)
+}
+
+// ParseGameType проверяет разновидность игры; пустая строка — холдем
+func ParseGameType(s string) (GameType, error) {
+	t := GameType(strings.ToLower(strings.TrimSpace(s)))
+	if t == "" {
+		return DefaultGameType, nil
+	}
+	if _, ok := variants[t]; !ok {
+		return "", fmt.Errorf("invalid game type %q: expected one of %s", s, strings.Join(GameTypeNames(), ", "))
+	}
+	return t, nil
+}
+
+// GameTypeNames — названия всех разновидностей по алфавиту
+func GameTypeNames() []string {
+	names := make([]string, 0, len(variants))
+	for t := range variants {
+		names = append(names, string(t))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get — правила разновидности; для неизвестной — холдем
+func Get(t GameType) Variant {
+	if v, ok := variants[t]; ok {
+		return v
+	}
+	return variants[DefaultGameType]
+}
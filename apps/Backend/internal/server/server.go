@@ -10,6 +10,7 @@ import (
 	_ "poker/docs"
 	"poker/internal/modules/auth"
 	daily_reward "poker/internal/modules/daily_rewards"
+	"poker/internal/modules/history"
 	"poker/internal/modules/room"
 	"poker/internal/modules/stats"
 )
@@ -69,4 +70,5 @@ func (s *AppServer) RegisterRoutes() {
 	room.RegisterRoutes(api, s.db, s.logger, s.temporal)
 	stats.RegisterRoutes(api, s.db, s.logger, s.temporal)
 	daily_reward.RegisterRoutes(api, s.db, s.logger, s.temporal)
+	history.RegisterRoutes(api, s.db, s.logger)
 }
@@ -19,10 +19,16 @@ func loadModels(sb *strings.Builder) {
 	models := []interface{}{
 		database.Account{},
 		database.AccountBalance{},
+		database.LedgerAccount{},
+		database.LedgerTransaction{},
+		database.LedgerEntry{},
 		database.Room{},
+		database.TableEscrow{},
 		database.GamePlayer{},
 		database.GameSession{},
 		database.GameMove{},
+		database.GameSnapshot{},
+		database.HandShare{},
 		database.Rating{},
 		database.Reward{},
 		database.RewardStatistic{},
@@ -0,0 +1,2 @@
+-- Modify "rooms" table
+ALTER TABLE "public"."rooms" ADD COLUMN "action_timeout" bigint NULL DEFAULT 30, ADD COLUMN "time_bank" bigint NULL DEFAULT 60;
@@ -0,0 +1,2 @@
+-- Modify "rooms" table
+ALTER TABLE "public"."rooms" ADD COLUMN "betting_structure" text NULL DEFAULT 'no-limit';
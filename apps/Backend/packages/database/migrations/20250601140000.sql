@@ -0,0 +1,2 @@
+-- Modify "rooms" table
+ALTER TABLE "public"."rooms" ADD COLUMN "game_type" text NULL DEFAULT 'holdem';
@@ -0,0 +1,2 @@
+-- Modify "rooms" table
+ALTER TABLE "public"."rooms" ADD COLUMN "rotation" text NULL, ADD COLUMN "rotation_hands" bigint NULL DEFAULT 0, ADD COLUMN "dealers_choice" boolean NULL DEFAULT false;
@@ -0,0 +1,16 @@
+-- Modify "rooms" table
+ALTER TABLE "public"."rooms" ADD COLUMN "min_buy_in" bigint NULL DEFAULT 0, ADD COLUMN "max_buy_in" bigint NULL DEFAULT 0;
+-- Create "table_escrows" table
+CREATE TABLE "public"."table_escrows" (
+  "id" text NOT NULL,
+  "room_id" text NOT NULL,
+  "user_id" text NOT NULL,
+  "buy_in" bigint NULL,
+  "cash_out" bigint NULL,
+  "status" text NULL DEFAULT 'held',
+  "created_at" bigint NULL,
+  "settled_at" bigint NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_table_escrows_room_user" to table: "table_escrows"
+CREATE INDEX "idx_table_escrows_room_user" ON "public"."table_escrows" ("room_id", "user_id");
@@ -0,0 +1,66 @@
+-- Modify "account_balances" table
+ALTER TABLE "public"."account_balances" ALTER COLUMN "current_balance" TYPE bigint USING COALESCE(NULLIF(TRIM("current_balance"), ''), '0')::bigint, ALTER COLUMN "current_balance" SET DEFAULT 0;
+-- Create "ledger_accounts" table
+CREATE TABLE "public"."ledger_accounts" (
+  "id" text NOT NULL,
+  "kind" text NOT NULL,
+  "owner_id" text NULL,
+  "balance" bigint NOT NULL DEFAULT 0,
+  "updated_at" bigint NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_ledger_accounts_kind" to table: "ledger_accounts"
+CREATE INDEX "idx_ledger_accounts_kind" ON "public"."ledger_accounts" ("kind");
+-- Create index "idx_ledger_accounts_owner_id" to table: "ledger_accounts"
+CREATE INDEX "idx_ledger_accounts_owner_id" ON "public"."ledger_accounts" ("owner_id");
+-- Create "ledger_transactions" table
+CREATE TABLE "public"."ledger_transactions" (
+  "id" text NOT NULL,
+  "reason" text NOT NULL,
+  "reference" text NULL,
+  "created_at" bigint NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_ledger_transactions_reason" to table: "ledger_transactions"
+CREATE INDEX "idx_ledger_transactions_reason" ON "public"."ledger_transactions" ("reason");
+-- Create index "idx_ledger_transactions_reference" to table: "ledger_transactions"
+CREATE INDEX "idx_ledger_transactions_reference" ON "public"."ledger_transactions" ("reference");
+-- Create "ledger_entries" table
+CREATE TABLE "public"."ledger_entries" (
+  "id" text NOT NULL,
+  "transaction_id" text NOT NULL,
+  "account_id" text NOT NULL,
+  "amount" bigint NOT NULL,
+  "balance_after" bigint NOT NULL,
+  "created_at" bigint NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_ledger_transactions_entries" FOREIGN KEY ("transaction_id") REFERENCES "public"."ledger_transactions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_ledger_entries_account_id" to table: "ledger_entries"
+CREATE INDEX "idx_ledger_entries_account_id" ON "public"."ledger_entries" ("account_id");
+-- Create index "idx_ledger_entries_transaction_id" to table: "ledger_entries"
+CREATE INDEX "idx_ledger_entries_transaction_id" ON "public"."ledger_entries" ("transaction_id");
+-- Open wallet accounts for existing balances
+INSERT INTO "public"."ledger_accounts" ("id", "kind", "owner_id", "balance", "updated_at")
+SELECT 'wallet:' || "user_id", 'wallet', "user_id", "current_balance", EXTRACT(EPOCH FROM NOW())::bigint
+FROM "public"."account_balances";
+-- Opening balances are booked as an adjustment so that all accounts still sum to zero
+INSERT INTO "public"."ledger_accounts" ("id", "kind", "owner_id", "balance", "updated_at")
+SELECT 'system:adjustments', 'system', NULL, -COALESCE(SUM("current_balance"), 0), EXTRACT(EPOCH FROM NOW())::bigint
+FROM "public"."account_balances";
+INSERT INTO "public"."ledger_transactions" ("id", "reason", "reference", "created_at")
+VALUES ('opening-balances', 'admin_adjustment', 'migration 20250601170000', EXTRACT(EPOCH FROM NOW())::bigint);
+INSERT INTO "public"."ledger_entries" ("id", "transaction_id", "account_id", "amount", "balance_after", "created_at")
+SELECT 'opening-' || "user_id", 'opening-balances', 'wallet:' || "user_id", "current_balance", "current_balance", EXTRACT(EPOCH FROM NOW())::bigint
+FROM "public"."account_balances"
+UNION ALL
+SELECT 'opening-system:adjustments', 'opening-balances', 'system:adjustments', -COALESCE(SUM("current_balance"), 0), -COALESCE(SUM("current_balance"), 0), EXTRACT(EPOCH FROM NOW())::bigint
+FROM "public"."account_balances";
+-- Ledger history is append-only
+CREATE FUNCTION "public"."ledger_append_only"() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  RAISE EXCEPTION 'ledger history is append-only: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$;
+CREATE TRIGGER "ledger_entries_append_only" BEFORE UPDATE OR DELETE ON "public"."ledger_entries" FOR EACH ROW EXECUTE FUNCTION "public"."ledger_append_only"();
+CREATE TRIGGER "ledger_transactions_append_only" BEFORE UPDATE OR DELETE ON "public"."ledger_transactions" FOR EACH ROW EXECUTE FUNCTION "public"."ledger_append_only"();
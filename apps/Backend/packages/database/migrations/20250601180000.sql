@@ -0,0 +1,4 @@
+-- Modify "ledger_transactions" table
+ALTER TABLE "public"."ledger_transactions" ADD COLUMN "idempotency_key" text NULL;
+-- Create index "idx_ledger_transactions_idempotency_key" to table: "ledger_transactions"
+CREATE UNIQUE INDEX "idx_ledger_transactions_idempotency_key" ON "public"."ledger_transactions" ("idempotency_key") WHERE (idempotency_key <> ''::text);
@@ -0,0 +1,2 @@
+-- Modify "rooms" table
+ALTER TABLE "public"."rooms" ADD COLUMN "rake_percent" numeric NULL DEFAULT 0, ADD COLUMN "rake_cap" bigint NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- Modify "game_sessions" table
+ALTER TABLE "public"."game_sessions" ADD COLUMN "rake" bigint NULL, ADD COLUMN "game_type" text NULL, ADD COLUMN "betting_structure" text NULL, ADD COLUMN "blinds" text NULL, ADD COLUMN "dealer" text NULL, ADD COLUMN "board" text NULL, ADD COLUMN "results" jsonb NULL, ADD COLUMN "started_at" bigint NULL;
+-- Create index "idx_game_sessions_room_id" to table: "game_sessions"
+CREATE INDEX "idx_game_sessions_room_id" ON "public"."game_sessions" ("room_id");
+-- Modify "game_players" table
+ALTER TABLE "public"."game_players" ADD COLUMN "hole_cards" text NULL, ADD COLUMN "up_cards" text NULL, ADD COLUMN "won" bigint NULL, ADD COLUMN "hand" text NULL, ADD CONSTRAINT "fk_game_sessions_players" FOREIGN KEY ("game_id") REFERENCES "public"."game_sessions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION;
+-- Create index "idx_game_players_game_id" to table: "game_players"
+CREATE INDEX "idx_game_players_game_id" ON "public"."game_players" ("game_id");
+-- Create index "idx_game_players_user_id" to table: "game_players"
+CREATE INDEX "idx_game_players_user_id" ON "public"."game_players" ("user_id");
+-- Modify "game_moves" table
+ALTER TABLE "public"."game_moves" ADD COLUMN "seq" bigint NULL, ADD COLUMN "street" text NULL, ADD COLUMN "total" bigint NULL, ADD COLUMN "is_all_in" boolean NULL, ADD COLUMN "discarded" bigint NULL, ADD CONSTRAINT "fk_game_sessions_moves" FOREIGN KEY ("game_id") REFERENCES "public"."game_sessions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION;
+-- Create index "idx_game_moves_game_id" to table: "game_moves"
+CREATE INDEX "idx_game_moves_game_id" ON "public"."game_moves" ("game_id");
@@ -0,0 +1,15 @@
+-- Create "game_snapshots" table
+CREATE TABLE "public"."game_snapshots" (
+  "id" text NOT NULL,
+  "game_id" text NOT NULL,
+  "seq" bigint NULL,
+  "action_seq" bigint NULL,
+  "street" text NULL,
+  "state" jsonb NULL,
+  "cards" jsonb NULL,
+  "created_at" bigint NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_game_sessions_snapshots" FOREIGN KEY ("game_id") REFERENCES "public"."game_sessions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_game_snapshots_game_id" to table: "game_snapshots"
+CREATE INDEX "idx_game_snapshots_game_id" ON "public"."game_snapshots" ("game_id");
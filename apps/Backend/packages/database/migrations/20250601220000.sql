@@ -0,0 +1,16 @@
+-- Create "hand_shares" table
+CREATE TABLE "public"."hand_shares" (
+  "token" text NOT NULL,
+  "game_id" text NOT NULL,
+  "owner_id" text NOT NULL,
+  "show_names" boolean NULL,
+  "show_folded_cards" boolean NULL,
+  "created_at" bigint NULL,
+  "revoked_at" bigint NULL,
+  PRIMARY KEY ("token"),
+  CONSTRAINT "fk_hand_shares_game" FOREIGN KEY ("game_id") REFERENCES "public"."game_sessions" ("id") ON UPDATE NO ACTION ON DELETE NO ACTION
+);
+-- Create index "idx_hand_shares_game_id" to table: "hand_shares"
+CREATE INDEX "idx_hand_shares_game_id" ON "public"."hand_shares" ("game_id");
+-- Create index "idx_hand_shares_owner_id" to table: "hand_shares"
+CREATE INDEX "idx_hand_shares_owner_id" ON "public"."hand_shares" ("owner_id");
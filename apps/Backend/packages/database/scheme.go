@@ -21,7 +21,37 @@ type AccountBalance struct {
 	ID             string   `gorm:"primaryKey"`
 	UserID         string   `gorm:"not null;unique"`
 	User           *Account `gorm:"foreignKey:UserID;references:ID"`
-	CurrentBalance string   `gorm:"not null"`
+	CurrentBalance int64    `gorm:"not null;default:0"` // проекция счёта кошелька в книге фишек
+}
+
+// LedgerAccount — счёт книги фишек. Balance — проекция проводок, напрямую не меняется
+type LedgerAccount struct {
+	ID        string `gorm:"primaryKey"`     // wallet:<user> / table:<room>:<user> / house / system:<name>
+	Kind      string `gorm:"not null;index"` // wallet / table / house / system
+	OwnerID   string `gorm:"index"`
+	Balance   int64  `gorm:"not null;default:0"`
+	UpdatedAt int64
+}
+
+// LedgerTransaction — одна операция с фишками; сумма её проводок всегда равна нулю
+type LedgerTransaction struct {
+	ID        string `gorm:"primaryKey"`
+	Reason    string `gorm:"not null;index"` // buy_in / cash_out / win / rake / daily_reward / admin_adjustment
+	Reference string `gorm:"index"`          // комната, раздача или награда
+	// Ключ идемпотентности: повтор операции с тем же ключом не проводится второй раз
+	IdempotencyKey string `gorm:"uniqueIndex:idx_ledger_transactions_idempotency_key,where:idempotency_key <> ''"`
+	CreatedAt      int64
+	Entries        []LedgerEntry `gorm:"foreignKey:TransactionID;references:ID"`
+}
+
+// LedgerEntry — проводка по счёту. Таблица только дописывается
+type LedgerEntry struct {
+	ID            string `gorm:"primaryKey"`
+	TransactionID string `gorm:"not null;index"`
+	AccountID     string `gorm:"not null;index"`
+	Amount        int64  `gorm:"not null"` // > 0 — приход, < 0 — расход
+	BalanceAfter  int64  `gorm:"not null"`
+	CreatedAt     int64
 }
 
 type Room struct {
@@ -33,38 +63,115 @@ type Room struct {
 	Users      []Account `gorm:"many2many:room_users;"`
 	Name       string
 	Status     string `gorm:"default:waiting"` // waiting / playing / finished
+
+	GameType         string `gorm:"default:holdem"`   // holdem / omaha / holdem-hilo / omaha-hilo / short-deck / stud / stud-hilo / 2-7-triple-draw
+	BettingStructure string `gorm:"default:no-limit"` // no-limit / pot-limit / fixed-limit
+
+	Rotation      string // смешанный стол: "hose" или список "holdem:fixed-limit,stud"; пусто — одна игра
+	RotationHands int    `gorm:"default:0"`     // раздач на каждую игру, 0 — круг
+	DealersChoice bool   `gorm:"default:false"` // следующую игру выбирает баттон
+
+	MinBuyIn int64 `gorm:"default:0"` // 0 — 20 больших блайндов
+	MaxBuyIn int64 `gorm:"default:0"` // 0 — 100 больших блайндов
+
+	RakePercent float64 `gorm:"default:0"` // процент каждого банка в пользу заведения, только кэш-столы
+	RakeCap     int64   `gorm:"default:0"` // потолок рейка за раздачу, 0 — без потолка
+
+	ActionTimeout int `gorm:"default:30"` // секунд на ход
+	TimeBank      int `gorm:"default:60"` // банк времени игрока, секунд
 }
 
+// TableEscrow — фишки игрока на столе: списаны с кошелька при бай-ине,
+// остаток стека возвращается в кошелёк одним расчётом при уходе или закрытии стола
+type TableEscrow struct {
+	ID        string `gorm:"primaryKey"`
+	RoomID    string `gorm:"not null;index:idx_table_escrows_room_user"`
+	UserID    string `gorm:"not null;index:idx_table_escrows_room_user"`
+	BuyIn     int64  // всего внесено, с докупками
+	CashOut   int64  // сколько вернулось в кошелёк
+	Status    string `gorm:"default:held"` // held / settled
+	CreatedAt int64
+	SettledAt int64
+}
+
+// GamePlayer — участник сохранённой раздачи
 type GamePlayer struct {
 	ID         string  `gorm:"primaryKey"`
-	GameID     string  `gorm:"not null"`
-	UserID     string  `gorm:"not null"`
+	GameID     string  `gorm:"not null;index"`
+	UserID     string  `gorm:"not null;index"`
 	User       Account `gorm:"foreignKey:UserID;references:ID"`
 	SeatNumber int
 	Chips      int64 // сколько фишек у игрока на момент входа
 	IsFolded   bool
 	IsAllIn    bool
+	HoleCards  string // все карты игрока через пробел, закрытые и открытые
+	UpCards    string // открытые карты (стад)
+	Won        int64  // выигрыш после рейка
+	Hand       string // лучшая комбинация на вскрытии
 }
 
+// GameSession — сохранённая раздача
 type GameSession struct {
-	ID        string `gorm:"primaryKey"`
-	RoomID    string `gorm:"not null"`
-	Round     int
-	Pot       int64
-	Status    string // preflop / flop / turn / river / showdown / finished
-	CreatedAt int64
+	ID               string `gorm:"primaryKey"` // <room>-<номер раздачи>
+	RoomID           string `gorm:"not null;index"`
+	Round            int    // номер раздачи за столом
+	Pot              int64
+	Rake             int64
+	Status           string // улица, на которой раздача закончилась: preflop / flop / turn / river / showdown
+	GameType         string
+	BettingStructure string
+	Blinds           string // "1/2" или "1/2/1" с анте
+	Dealer           string
+	Board            string // карты борда через пробел
+	Results          string `gorm:"type:jsonb"` // банки и выплаты
+	StartedAt        int64
+	CreatedAt        int64
+
+	Players   []GamePlayer   `gorm:"foreignKey:GameID;references:ID"`
+	Moves     []GameMove     `gorm:"foreignKey:GameID;references:ID"`
+	Snapshots []GameSnapshot `gorm:"foreignKey:GameID;references:ID"`
 }
 
+// GameMove — действие в сохранённой раздаче
 type GameMove struct {
 	ID          string `gorm:"primaryKey"`
-	GameID      string `gorm:"not null"`
+	GameID      string `gorm:"not null;index"`
 	PlayerID    string `gorm:"not null"`
-	Action      string // fold / call / raise / check / allin
-	Amount      int64
-	RoundNumber int
+	Seq         int    // порядок действий в раздаче
+	Street      string
+	Action      string // ante / small-blind / big-blind / bring-in / fold / check / call / bet / raise / allin / draw
+	Amount      int64  // фишек добавлено в банк этим действием
+	Total       int64  // ставка игрока на улице после действия
+	IsAllIn     bool
+	Discarded   int // дро: сколько карт сменил игрок
+	RoundNumber int // номер улицы с нуля
 	CreatedAt   int64
 }
 
+// GameSnapshot — стол после очередного шага раздачи, кадр пошагового повтора
+type GameSnapshot struct {
+	ID        string `gorm:"primaryKey"`
+	GameID    string `gorm:"not null;index"`
+	Seq       int    // номер кадра; нулевой — стол сразу после раздачи карт
+	ActionSeq int    // GameMove.Seq последнего сыгранного действия
+	Street    string
+	State     string `gorm:"type:jsonb"` // открытая часть стола: стеки, ставки, банк, борд, чей ход
+	Cards     string `gorm:"type:jsonb"` // закрытые карты игроков; отдаются с учётом того, кто смотрит
+	CreatedAt int64
+}
+
+// HandShare — публичная ссылка на сыгранную раздачу
+type HandShare struct {
+	Token           string      `gorm:"primaryKey"` // неугадываемый токен из ссылки
+	GameID          string      `gorm:"not null;index"`
+	Game            GameSession `gorm:"foreignKey:GameID;references:ID"`
+	OwnerID         string      `gorm:"not null;index"` // участник раздачи, который её опубликовал
+	ShowNames       bool        // показывать ники остальных игроков
+	ShowFoldedCards bool        // показывать карты владельца, даже если он их сбросил
+	CreatedAt       int64
+	RevokedAt       int64 // 0 — ссылка действует
+}
+
 // 📊 Рейтинг игрока
 type Rating struct {
 	ID      string  `gorm:"primaryKey"`